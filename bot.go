@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/elliotwms/bot/interactions/migrator"
 	"github.com/elliotwms/bot/interactions/router"
 	"github.com/elliotwms/bot/log"
+	"github.com/elliotwms/bot/report"
 )
 
 type Bot struct {
@@ -20,6 +22,7 @@ type Bot struct {
 	handlerRemovers []func()
 	router          *router.Router
 	migrator        *migrator.Migrator
+	reporter        report.Reporter
 }
 
 // Run runs the bot, starts the session if not already started, serves the health endpoint if present, and blocks
@@ -31,9 +34,14 @@ func (bot *Bot) Run(ctx context.Context) error {
 		bot.session.Identify.Intents = bot.intents
 	}
 
-	// add the router handler for InteractionCreate events
+	// add the router handlers for InteractionCreate and entitlement events
 	if bot.router != nil {
-		bot.handlerRemovers = append(bot.handlerRemovers, bot.session.AddHandler(bot.router.Handle))
+		bot.handlerRemovers = append(bot.handlerRemovers,
+			bot.session.AddHandler(bot.router.Handle),
+			bot.session.AddHandler(bot.router.HandleEntitlementCreate),
+			bot.session.AddHandler(bot.router.HandleEntitlementUpdate),
+			bot.session.AddHandler(bot.router.HandleEntitlementDelete),
+		)
 	}
 
 	if bot.migrator != nil {
@@ -41,6 +49,7 @@ func (bot *Bot) Run(ctx context.Context) error {
 		err := bot.migrator.Migrate(ctx)
 		if err != nil {
 			bot.log.Error("Failed to migrate application commands: " + err.Error())
+			bot.report(ctx, "migration", err)
 			return err
 		}
 	}
@@ -51,6 +60,7 @@ func (bot *Bot) Run(ctx context.Context) error {
 	if err := bot.session.Open(); err != nil {
 		if !errors.Is(err, discordgo.ErrWSAlreadyOpen) {
 			bot.log.Error("Could not open session", log.WithErr(err))
+			bot.report(ctx, "session", err)
 
 			return err
 		}
@@ -66,10 +76,16 @@ func (bot *Bot) Run(ctx context.Context) error {
 	<-ctx.Done()
 
 	bot.log.Info("Shutting down")
+	if bot.router != nil {
+		// stop any collectors waiting for interactions
+		bot.router.Close()
+	}
+
 	if shouldCloseSession {
 		bot.log.Debug("Closing session")
 		if err := bot.session.Close(); err != nil {
 			bot.log.Error("Could not close session", log.WithErr(err))
+			bot.report(ctx, "session", err)
 			return err
 		}
 	}
@@ -80,3 +96,12 @@ func (bot *Bot) Run(ctx context.Context) error {
 
 	return nil
 }
+
+// report reports the error, if the bot has a reporter
+func (bot *Bot) report(ctx context.Context, source string, err error) {
+	if bot.reporter == nil {
+		return
+	}
+
+	bot.reporter.Report(context.WithoutCancel(ctx), report.Report{Err: err, Source: source, Time: time.Now()})
+}
@@ -1,13 +1,28 @@
 package bot
 
 import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"maps"
+	"net"
+	"slices"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/features"
+	"github.com/elliotwms/bot/i18n"
 	"github.com/elliotwms/bot/interactions/migrator"
 	"github.com/elliotwms/bot/interactions/router"
 	"github.com/elliotwms/bot/log"
+	"github.com/elliotwms/bot/report"
+)
+
+var (
+	ErrMissingSession         = errors.New("missing session")
+	ErrMissingApplicationID   = errors.New("missing application ID")
+	ErrInvalidHealthCheckAddr = errors.New("invalid health check address")
 )
 
 // Builder builds a Bot.
@@ -23,8 +38,13 @@ type Builder struct {
 	migrator         *migrator.Migrator
 	guildID          string
 	handlers         []interface{}
-	commands         map[*discordgo.ApplicationCommand]router.ApplicationCommandHandler
+	commands         []command
 	migrationEnabled bool
+	help             *helpCommand
+	catalog          *i18n.Catalog
+	reporter         report.Reporter
+	features         *features.Flags
+	featureMigration bool
 }
 
 func New(applicationID string, session *discordgo.Session) *Builder {
@@ -32,7 +52,6 @@ func New(applicationID string, session *discordgo.Session) *Builder {
 		session:       session,
 		log:           slog.New(log.DiscardHandler),
 		applicationID: applicationID,
-		commands:      make(map[*discordgo.ApplicationCommand]router.ApplicationCommandHandler),
 	}
 
 	return bot
@@ -98,19 +117,150 @@ func (b *Builder) WithMigrationEnabled(enabled bool) *Builder {
 	return b
 }
 
-func (b *Builder) WithApplicationCommand(c *discordgo.ApplicationCommand, h router.ApplicationCommandHandler) *Builder {
-	b.commands[c] = h
+// WithApplicationCommand registers the application command and its handler, configured by the options.
+func (b *Builder) WithApplicationCommand(c *discordgo.ApplicationCommand, h router.ApplicationCommandHandler, opts ...CommandOption) *Builder {
+	cmd := command{definition: c, handler: h}
+	for _, o := range opts {
+		o(&cmd)
+	}
+
+	b.commands = append(b.commands, cmd)
 
 	return b
 }
 
+// WithApplicationCommands registers each of the application commands, ordered by name and type.
 func (b *Builder) WithApplicationCommands(handlers map[*discordgo.ApplicationCommand]router.ApplicationCommandHandler) *Builder {
-	maps.Copy(b.commands, handlers)
+	commands := slices.SortedFunc(maps.Keys(handlers), func(a, b *discordgo.ApplicationCommand) int {
+		return cmp.Or(
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Type, b.Type),
+		)
+	})
+
+	for _, c := range commands {
+		b.WithApplicationCommand(c, handlers[c])
+	}
+
+	return b
+}
+
+// WithCommand registers the command with the router, including any autocomplete, component and modal handlers it
+// owns, and with the migrator, configured by the options.
+func (b *Builder) WithCommand(c Command, opts ...CommandOption) *Builder {
+	cmd := newCommand(c)
+	for _, o := range opts {
+		o(&cmd)
+	}
+
+	b.commands = append(b.commands, cmd)
+
+	return b
+}
+
+func (b *Builder) WithCommands(cs ...Command) *Builder {
+	for _, c := range cs {
+		b.WithCommand(c)
+	}
+
+	return b
+}
+
+// WithCatalog localizes the names and descriptions of the bot's application commands before they are migrated, and
+// makes a translator for each interaction's locale available to handlers via i18n.FromContext. See
+// i18n.Catalog.LocalizeCommand for how messages are keyed.
+func (b *Builder) WithCatalog(c *i18n.Catalog) *Builder {
+	b.catalog = c
+
+	return b
+}
+
+// WithReporter reports errors and panics from the router's handlers, and errors which stop the bot running (e.g. failing
+// to migrate commands), alongside their context. See report.NewWebhook to post reports to a Discord channel.
+func (b *Builder) WithReporter(rep report.Reporter) *Builder {
+	b.reporter = rep
+
+	return b
+}
+
+// WithFeatures evaluates the feature flags of commands gated with WithFeature. Invocations from guilds and users a
+// command's flag is not enabled for are answered with the flags' reply instead of reaching the handler.
+func (b *Builder) WithFeatures(f *features.Flags) *Builder {
+	b.features = f
+
+	return b
+}
+
+// WithFeatureMigration migrates commands gated with WithFeature only to the guilds their flag is enabled in, instead of
+// globally. Commands whose flag may be enabled outside of its guilds (see features.Flag.Global) are still migrated
+// globally. Flags are evaluated when the bot is run, so changes to a flag's guilds take effect on the next run. To
+// remove commands from the guilds a flag has been disabled in since the last run, provide a migrator which persists the
+// guilds it migrated to with migrator.WithGuildStore (see WithMigrator).
+func (b *Builder) WithFeatureMigration(enabled bool) *Builder {
+	b.featureMigration = enabled
 
 	return b
 }
 
+// WithHelpCommand registers a /help command which lists the bot's application commands, including their options and
+// subcommands. Commands are filtered to those the invoking member can use, including their feature flags (see
+// WithFeature) and SKUs (see router.RequireSKU).
+func (b *Builder) WithHelpCommand(opts ...HelpOption) *Builder {
+	b.help = newHelpCommand(opts...)
+
+	return b
+}
+
+// Validate checks the builder's configuration, returning every problem found.
+func (b *Builder) Validate() error {
+	var errs []error
+
+	if b.session == nil {
+		errs = append(errs, ErrMissingSession)
+	}
+
+	if b.applicationID == "" && (b.migrationEnabled || b.migrator != nil) {
+		errs = append(errs, fmt.Errorf("%w: required to migrate application commands", ErrMissingApplicationID))
+	}
+
+	if b.healthCheckAddr != nil {
+		if err := validateAddr(*b.healthCheckAddr); err != nil {
+			errs = append(errs, fmt.Errorf("%w %q: %w", ErrInvalidHealthCheckAddr, *b.healthCheckAddr, err))
+		}
+	}
+
+	errs = append(errs, validateCommands(b.allCommands()))
+
+	if b.features == nil {
+		for _, c := range b.commands {
+			if c.feature != "" {
+				errs = append(errs, fmt.Errorf("%w: %q is gated by feature %q, use WithFeatures", ErrMissingFeatures, c.definition.Name, c.feature))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// BuildE validates the builder's configuration and builds the Bot, returning every configuration problem found instead.
+func (b *Builder) BuildE() (*Bot, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+
+	return b.build(), nil
+}
+
+// Build builds the Bot. Configuration problems reported by Validate are logged, use BuildE to handle them instead.
 func (b *Builder) Build() *Bot {
+	if err := b.Validate(); err != nil {
+		b.log.Error("Invalid configuration", log.WithErr(err))
+	}
+
+	return b.build()
+}
+
+func (b *Builder) build() *Bot {
 	bot := &Bot{
 		session:         b.session,
 		log:             b.log,
@@ -119,16 +269,33 @@ func (b *Builder) Build() *Bot {
 		intents:         b.intents,
 		router:          b.router,
 		migrator:        b.migrator,
+		reporter:        b.reporter,
 	}
 
 	for _, h := range b.handlers {
 		bot.handlerRemovers = append(bot.handlerRemovers, bot.session.AddHandler(h))
 	}
 
+	commands := b.allCommands()
+
+	if b.catalog != nil {
+		for _, c := range commands {
+			b.catalog.LocalizeCommand(c.definition)
+		}
+
+		if bot.router != nil {
+			router.WithCatalog(b.catalog)(bot.router)
+		}
+	}
+
+	if b.reporter != nil && bot.router != nil {
+		router.WithReporter(b.reporter)(bot.router)
+	}
+
 	// register application commands with the router and migrator
-	if len(b.commands) > 0 {
+	if len(commands) > 0 {
 		if bot.router == nil {
-			bot.router = router.New(router.WithLogger(bot.log))
+			bot.router = router.New(router.WithLogger(bot.log), router.WithCatalog(b.catalog), router.WithReporter(b.reporter))
 		}
 
 		if bot.migrator == nil && b.migrationEnabled {
@@ -139,14 +306,61 @@ func (b *Builder) Build() *Bot {
 			)
 		}
 
-		for c, h := range b.commands {
-			bot.router.RegisterCommand(c.Name, c.Type, h)
+		for _, c := range commands {
+			c.register(bot.router, b.features)
+
+			if bot.migrator == nil {
+				continue
+			}
+
+			switch {
+			case c.feature != "" && b.features == nil:
+				// the command's feature is disabled for everyone without flags, so there is nowhere to migrate it to
+				continue
+			case c.feature != "" && b.featureMigration:
+				// only migrate the command to the guilds its feature is enabled in
+				bot.migrator.WithGuildApplicationCommand(c.definition, func(ctx context.Context) ([]string, bool, error) {
+					return b.features.Guilds(ctx, c.feature)
+				})
+			default:
+				bot.migrator.WithApplicationCommand(c.definition)
+			}
+		}
+	}
+
+	if b.help != nil {
+		b.help.register(bot.router)
+		b.help.features = b.features
+		b.help.commands = append(b.help.commands, commands...)
 
-			if bot.migrator != nil {
-				bot.migrator.WithApplicationCommand(c)
+		for _, r := range bot.router.Routes() {
+			// commands registered without a type are chat input commands, see commandType
+			if r.Type == discordgo.InteractionApplicationCommand && (r.CommandType == 0 || r.CommandType == discordgo.ChatApplicationCommand) {
+				b.help.skus[r.Name] = r.SKUs
 			}
 		}
 	}
 
 	return bot
 }
+
+// allCommands returns the registered application commands, including any built-in commands.
+func (b *Builder) allCommands() []command {
+	if b.help == nil {
+		return b.commands
+	}
+
+	return append(slices.Clone(b.commands), command{definition: b.help.definition(), handler: b.help.handle})
+}
+
+// validateAddr checks that addr is a valid TCP address to listen on, e.g. ":8080" or "127.0.0.1:8080".
+func validateAddr(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+
+	_, err = net.LookupPort("tcp", port)
+
+	return err
+}
@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/i18n"
+	"github.com/elliotwms/bot/report"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_BuildE(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	b, err := New(appID, s).
+		WithHealthCheck(":8081").
+		WithMigrationEnabled(true).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}, nil).
+		BuildE()
+
+	require.NoError(t, err)
+	require.NotNil(t, b)
+}
+
+func TestBuilder_BuildE_InvalidConfiguration(t *testing.T) {
+	b, err := New("", nil).
+		WithHealthCheck("localhost").
+		WithMigrationEnabled(true).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "Foo", Type: discordgo.ChatApplicationCommand}, nil).
+		BuildE()
+
+	require.Nil(t, b)
+	require.ErrorIs(t, err, ErrMissingSession)
+	require.ErrorIs(t, err, ErrMissingApplicationID)
+	require.ErrorIs(t, err, ErrInvalidHealthCheckAddr)
+	require.ErrorIs(t, err, ErrInvalidCommandName)
+}
+
+func TestBuilder_Validate_ApplicationIDWithoutMigration(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	err := New("", s).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}, nil).
+		Validate()
+
+	require.NoError(t, err)
+}
+
+func TestBuilder_Validate_HealthCheckAddr(t *testing.T) {
+	tests := map[string]bool{
+		":8080":          true,
+		"127.0.0.1:8080": true,
+		"[::1]:8080":     true,
+		":http":          true,
+		"localhost":      false,
+		":99999":         false,
+		":port":          false,
+	}
+
+	for addr, valid := range tests {
+		t.Run(addr, func(t *testing.T) {
+			s, _ := discordgo.New("token")
+
+			err := New(appID, s).WithHealthCheck(addr).Validate()
+
+			if valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrInvalidHealthCheckAddr)
+			}
+		})
+	}
+}
+
+func TestBuilder_WithCatalog(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	c := i18n.New(discordgo.EnglishUS)
+	c.Add(discordgo.French, map[string]string{"commands.foo.description": "Le foo"})
+
+	foo := &discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand, Description: "The foo"}
+
+	_, err := New(appID, s).
+		WithCatalog(c).
+		WithApplicationCommand(foo, nil).
+		BuildE()
+
+	require.NoError(t, err)
+	require.Equal(t, &map[discordgo.Locale]string{discordgo.French: "Le foo"}, foo.DescriptionLocalizations)
+}
+
+func TestBuilder_WithReporter(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	var reports []report.Report
+
+	b, err := New(appID, s).
+		WithReporter(report.ReporterFunc(func(_ context.Context, r report.Report) {
+			reports = append(reports, r)
+		})).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}, func(context.Context, *discordgo.Session, *discordgo.InteractionCreate, discordgo.ApplicationCommandInteractionData) error {
+			panic("oops")
+		}).
+		BuildE()
+	require.NoError(t, err)
+
+	b.router.Handle(s, &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type: discordgo.InteractionApplicationCommand,
+		Data: discordgo.ApplicationCommandInteractionData{Name: "foo", CommandType: discordgo.ChatApplicationCommand},
+	}})
+
+	require.Len(t, reports, 1)
+	require.True(t, reports[0].Panicked())
+	require.Equal(t, "foo", reports[0].Route)
+}
@@ -0,0 +1,252 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/features"
+	"github.com/elliotwms/bot/interactions/router"
+)
+
+var (
+	ErrInvalidCommandName = errors.New("invalid command name")
+	ErrDuplicateCommand   = errors.New("duplicate command")
+	ErrTooManyCommands    = errors.New("too many commands")
+	ErrInvalidContexts    = errors.New("invalid command contexts")
+	ErrInvalidOptions     = errors.New("invalid command options")
+	ErrMissingFeatures    = errors.New("missing feature flags")
+)
+
+// chatCommandName matches valid chat input command names, see
+// https://discord.com/developers/docs/interactions/application-commands#application-command-object-application-command-naming
+var chatCommandName = regexp.MustCompile(`^[-_'\p{L}\p{N}\p{Devanagari}\p{Thai}]{1,32}$`)
+
+// commandLimits are the maximum number of commands of each type an application can register
+var commandLimits = []struct {
+	commandType discordgo.ApplicationCommandType
+	limit       int
+}{
+	{discordgo.ChatApplicationCommand, 100},
+	{discordgo.UserApplicationCommand, 15},
+	{discordgo.MessageApplicationCommand, 15},
+}
+
+// Command is an application command which bundles its definition with its handler. Commands may also implement
+// AutocompleteCommand, ComponentCommand and ModalCommand to handle the interactions they own.
+type Command interface {
+	// Definition returns the application command to register
+	Definition() *discordgo.ApplicationCommand
+	// Handle handles invocations of the command
+	Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error
+}
+
+// AutocompleteCommand is a Command with options which provide autocomplete choices.
+type AutocompleteCommand interface {
+	Command
+	Autocomplete(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) ([]*discordgo.ApplicationCommandOptionChoice, error)
+}
+
+// ComponentCommand is a Command which owns message components, returning their handlers keyed by custom ID.
+type ComponentCommand interface {
+	Command
+	Components() map[string]router.ComponentHandler
+}
+
+// ModalCommand is a Command which owns modals, returning their handlers keyed by custom ID.
+type ModalCommand interface {
+	Command
+	Modals() map[string]router.ModalSubmitHandler
+}
+
+// CommandOption configures an application command as it is registered with the builder.
+type CommandOption func(*command)
+
+// WithInstallTypes sets where the app must be installed for the command to be available: to a guild, to a user, or
+// either. Only global commands support install types, so they are not migrated to a guild (see WithGuildID).
+func WithInstallTypes(types ...discordgo.ApplicationIntegrationType) CommandOption {
+	return func(c *command) {
+		c.definition.IntegrationTypes = &types
+	}
+}
+
+// WithContexts sets where the command can be used: in guilds, in DMs with the bot, or in group DMs and DMs with other
+// users (which requires the user install type). The router answers invocations from other contexts with an ephemeral
+// error. Only global commands support contexts, so they are not migrated to a guild (see WithGuildID).
+func WithContexts(contexts ...discordgo.InteractionContextType) CommandOption {
+	return func(c *command) {
+		c.definition.Contexts = &contexts
+	}
+}
+
+// WithRouteOptions registers the command with the router with the route options, e.g. router.RequireSKU.
+func WithRouteOptions(opts ...router.RouteOption) CommandOption {
+	return func(c *command) {
+		c.routeOptions = append(c.routeOptions, opts...)
+	}
+}
+
+// WithFeature gates the command behind the feature flag, so that it is only available to the guilds and users the flag
+// is enabled for (see Builder.WithFeatures). With Builder.WithFeatureMigration the command is only migrated to the
+// guilds the flag is enabled in. Without Builder.WithFeatures the command is unavailable to everyone, and is not
+// migrated.
+func WithFeature(name string) CommandOption {
+	return func(c *command) {
+		c.feature = name
+	}
+}
+
+type command struct {
+	definition   *discordgo.ApplicationCommand
+	handler      router.ApplicationCommandHandler
+	autocomplete router.AutocompleteHandler
+	components   map[string]router.ComponentHandler
+	modals       map[string]router.ModalSubmitHandler
+	routeOptions []router.RouteOption
+	// feature is the name of the feature flag the command is gated behind, if any
+	feature string
+}
+
+func newCommand(c Command) command {
+	cmd := command{definition: c.Definition(), handler: c.Handle}
+
+	if ac, ok := c.(AutocompleteCommand); ok {
+		cmd.autocomplete = ac.Autocomplete
+	}
+
+	if cc, ok := c.(ComponentCommand); ok {
+		cmd.components = cc.Components()
+	}
+
+	if mc, ok := c.(ModalCommand); ok {
+		cmd.modals = mc.Modals()
+	}
+
+	return cmd
+}
+
+// register adds the command's routes to the router, gating the command with the flags if it has a feature. Without
+// flags, the command's feature is disabled for everyone.
+func (c command) register(r *router.Router, flags *features.Flags) {
+	opts := slices.Clone(c.routeOptions)
+	if c.definition.Contexts != nil {
+		opts = append(opts, router.WithContexts(*c.definition.Contexts...))
+	}
+
+	if c.feature != "" {
+		if flags == nil {
+			flags = features.New(features.NewMemoryStore())
+		}
+
+		opts = append(opts, features.Require(flags, c.feature))
+	}
+
+	r.RegisterCommand(c.definition.Name, c.definition.Type, c.handler, opts...)
+
+	if c.autocomplete != nil {
+		r.RegisterAutocomplete(c.definition.Name, c.autocomplete)
+	}
+
+	for customID, h := range c.components {
+		r.RegisterComponent(customID, h)
+	}
+
+	for customID, h := range c.modals {
+		r.RegisterModal(customID, h)
+	}
+}
+
+// validateCommands checks the commands for invalid names, duplicate name and type pairs, contexts which the install
+// types do not allow, invalid route options, context menu commands with options, and that the number of commands of each type is within
+// Discord's limits.
+func validateCommands(commands []command) error {
+	var errs []error
+
+	type key struct {
+		name        string
+		commandType discordgo.ApplicationCommandType
+	}
+
+	seen := make(map[key]bool)
+	counts := make(map[discordgo.ApplicationCommandType]int)
+
+	for _, c := range commands {
+		t := commandType(c.definition)
+
+		if err := validateCommandName(c.definition.Name, t); err != nil {
+			errs = append(errs, err)
+		}
+
+		if err := validateContexts(c.definition); err != nil {
+			errs = append(errs, err)
+		}
+
+		if err := router.ValidateRouteOptions(c.routeOptions...); err != nil {
+			errs = append(errs, fmt.Errorf("%q: %w", c.definition.Name, err))
+		}
+
+		if t != discordgo.ChatApplicationCommand && len(c.definition.Options) > 0 {
+			errs = append(errs, fmt.Errorf("%w: %q is a context menu command, which can not have options", ErrInvalidOptions, c.definition.Name))
+		}
+
+		k := key{name: c.definition.Name, commandType: t}
+		if seen[k] {
+			errs = append(errs, fmt.Errorf("%w: %q of type %d is registered more than once", ErrDuplicateCommand, c.definition.Name, t))
+		}
+		seen[k] = true
+
+		counts[t]++
+	}
+
+	for _, l := range commandLimits {
+		if counts[l.commandType] > l.limit {
+			errs = append(errs, fmt.Errorf("%w: %d commands of type %d registered, the limit is %d", ErrTooManyCommands, counts[l.commandType], l.commandType, l.limit))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateContexts checks that a command which can be used in private channels can be installed to users, as only user
+// installs are available there.
+func validateContexts(c *discordgo.ApplicationCommand) error {
+	if c.Contexts == nil || c.IntegrationTypes == nil {
+		return nil
+	}
+
+	if slices.Contains(*c.Contexts, discordgo.InteractionContextPrivateChannel) && !slices.Contains(*c.IntegrationTypes, discordgo.ApplicationIntegrationUserInstall) {
+		return fmt.Errorf("%w: %q can be used in private channels, which requires the user install type", ErrInvalidContexts, c.Name)
+	}
+
+	return nil
+}
+
+func validateCommandName(name string, t discordgo.ApplicationCommandType) error {
+	if t == discordgo.ChatApplicationCommand {
+		if !chatCommandName.MatchString(name) || strings.ToLower(name) != name {
+			return fmt.Errorf("%w: %q must be 1-32 lowercase letters, numbers, hyphens or underscores", ErrInvalidCommandName, name)
+		}
+
+		return nil
+	}
+
+	if n := utf8.RuneCountInString(name); n < 1 || n > 32 {
+		return fmt.Errorf("%w: %q must be 1-32 characters", ErrInvalidCommandName, name)
+	}
+
+	return nil
+}
+
+// commandType returns the command's type, where commands without a type are chat input commands.
+func commandType(c *discordgo.ApplicationCommand) discordgo.ApplicationCommandType {
+	if c.Type == 0 {
+		return discordgo.ChatApplicationCommand
+	}
+
+	return c.Type
+}
@@ -0,0 +1,271 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/features"
+	"github.com/elliotwms/bot/interactions/router"
+	"github.com/elliotwms/bot/interactions/router/routertest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_Validate(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	err := New(appID, s).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}, nil).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "Foo", Type: discordgo.UserApplicationCommand}, nil).
+		Validate()
+
+	require.NoError(t, err)
+}
+
+func TestBuilder_Validate_DuplicateCommand(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	err := New(appID, s).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}, nil).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo"}, nil).
+		Validate()
+
+	require.ErrorIs(t, err, ErrDuplicateCommand)
+}
+
+func TestBuilder_Validate_DuplicateHelpCommand(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	err := New(appID, s).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "help", Type: discordgo.ChatApplicationCommand}, nil).
+		WithHelpCommand().
+		Validate()
+
+	require.ErrorIs(t, err, ErrDuplicateCommand)
+}
+
+func TestBuilder_Validate_InvalidCommandName(t *testing.T) {
+	tests := map[string]*discordgo.ApplicationCommand{
+		"empty":             {Type: discordgo.ChatApplicationCommand},
+		"uppercase chat":    {Name: "Foo", Type: discordgo.ChatApplicationCommand},
+		"spaces in chat":    {Name: "foo bar", Type: discordgo.ChatApplicationCommand},
+		"too long":          {Name: "abcdefghijklmnopqrstuvwxyzabcdefg", Type: discordgo.ChatApplicationCommand},
+		"too long message":  {Name: "abcdefghijklmnopqrstuvwxyzabcdefg", Type: discordgo.MessageApplicationCommand},
+		"empty user":        {Type: discordgo.UserApplicationCommand},
+		"uppercase untyped": {Name: "Foo"},
+	}
+
+	for name, c := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, _ := discordgo.New("token")
+
+			err := New(appID, s).WithApplicationCommand(c, nil).Validate()
+
+			require.ErrorIs(t, err, ErrInvalidCommandName)
+		})
+	}
+}
+
+func TestBuilder_Validate_TooManyCommands(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	b := New(appID, s)
+	for i := range 16 {
+		b.WithApplicationCommand(&discordgo.ApplicationCommand{Name: fmt.Sprintf("user %d", i), Type: discordgo.UserApplicationCommand}, nil)
+	}
+
+	require.ErrorIs(t, b.Validate(), ErrTooManyCommands)
+}
+
+type testCommand struct{}
+
+func (testCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}
+}
+
+func (testCommand) Handle(context.Context, *discordgo.Session, *discordgo.InteractionCreate, discordgo.ApplicationCommandInteractionData) error {
+	return nil
+}
+
+func (testCommand) Autocomplete(context.Context, *discordgo.Session, *discordgo.InteractionCreate, discordgo.ApplicationCommandInteractionData) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	return nil, nil
+}
+
+func (testCommand) Components() map[string]router.ComponentHandler {
+	return map[string]router.ComponentHandler{"foo:button": nil}
+}
+
+func (testCommand) Modals() map[string]router.ModalSubmitHandler {
+	return map[string]router.ModalSubmitHandler{"foo:modal": nil}
+}
+
+func TestBuilder_WithCommand(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	b := New(appID, s).
+		WithMigrationEnabled(true).
+		WithCommand(testCommand{}).
+		Build()
+
+	require.Equal(t, []router.Route{
+		{Type: discordgo.InteractionApplicationCommand, Name: "foo", CommandType: discordgo.ChatApplicationCommand},
+		{Type: discordgo.InteractionMessageComponent, Name: "foo:button"},
+		{Type: discordgo.InteractionApplicationCommandAutocomplete, Name: "foo", CommandType: discordgo.ChatApplicationCommand},
+		{Type: discordgo.InteractionModalSubmit, Name: "foo:modal"},
+	}, b.router.Routes())
+	require.NotNil(t, b.migrator)
+}
+
+func TestBuilder_WithApplicationCommand_Contexts(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	c := &discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}
+
+	b := New(appID, s).
+		WithApplicationCommand(c, nil,
+			WithInstallTypes(discordgo.ApplicationIntegrationGuildInstall, discordgo.ApplicationIntegrationUserInstall),
+			WithContexts(discordgo.InteractionContextGuild, discordgo.InteractionContextPrivateChannel),
+		).
+		Build()
+
+	require.Equal(t, []discordgo.ApplicationIntegrationType{discordgo.ApplicationIntegrationGuildInstall, discordgo.ApplicationIntegrationUserInstall}, *c.IntegrationTypes)
+	require.Equal(t, []router.Route{
+		{
+			Type:        discordgo.InteractionApplicationCommand,
+			Name:        "foo",
+			CommandType: discordgo.ChatApplicationCommand,
+			Contexts:    []discordgo.InteractionContextType{discordgo.InteractionContextGuild, discordgo.InteractionContextPrivateChannel},
+		},
+	}, b.router.Routes())
+}
+
+func TestBuilder_Validate_InvalidContexts(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	err := New(appID, s).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}, nil,
+			WithInstallTypes(discordgo.ApplicationIntegrationGuildInstall),
+			WithContexts(discordgo.InteractionContextPrivateChannel),
+		).
+		Validate()
+
+	require.ErrorIs(t, err, ErrInvalidContexts)
+}
+
+func TestBuilder_WithCommand_RouteOptions(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	b := New(appID, s).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}, nil,
+			WithRouteOptions(router.RequireSKU("sku")),
+		).
+		Build()
+
+	require.Equal(t, []string{"sku"}, b.router.Routes()[0].SKUs)
+}
+
+func TestBuilder_Validate_InvalidVariants(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	err := New(appID, s).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}, nil,
+			WithRouteOptions(router.WithVariants(router.BucketByGuild, router.Variant{Name: "v2", Weight: -10})),
+		).
+		Validate()
+
+	require.ErrorIs(t, err, router.ErrInvalidVariants)
+}
+
+func TestBuilder_Validate_ContextMenuOptions(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	err := New(appID, s).
+		WithApplicationCommand(&discordgo.ApplicationCommand{
+			Name:    "Report",
+			Type:    discordgo.MessageApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{{Name: "reason", Type: discordgo.ApplicationCommandOptionString}},
+		}, nil).
+		Validate()
+
+	require.ErrorIs(t, err, ErrInvalidOptions)
+}
+
+func TestBuilder_Validate_MissingFeatures(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	err := New(appID, s).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}, nil, WithFeature("beta")).
+		Validate()
+
+	require.ErrorIs(t, err, ErrMissingFeatures)
+}
+
+func TestBuilder_WithFeature(t *testing.T) {
+	s, rec := routertest.NewSession()
+
+	called := false
+	b, err := New(appID, s).
+		WithFeatures(features.New(features.NewMemoryStore(), features.WithReply(func(context.Context, string) *discordgo.InteractionResponseData {
+			return &discordgo.InteractionResponseData{}
+		}))).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}, func(context.Context, *discordgo.Session, *discordgo.InteractionCreate, discordgo.ApplicationCommandInteractionData) error {
+			called = true
+			return nil
+		}, WithFeature("beta")).
+		BuildE()
+	require.NoError(t, err)
+
+	i := routertest.Command("foo", routertest.InGuild("guild"))
+	b.router.Handle(s, i)
+
+	require.False(t, called)
+	require.Len(t, rec.Responses(i.Token), 1)
+}
+
+func TestBuilder_WithFeature_MissingFeatures(t *testing.T) {
+	s, rec := routertest.NewSession()
+
+	var migrated []*discordgo.ApplicationCommand
+	transport := s.Client.Transport
+	s.Client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodPut && strings.HasSuffix(req.URL.Path, "/commands") {
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&migrated))
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("[]")), Request: req}, nil
+		}
+
+		return transport.RoundTrip(req)
+	})
+
+	called := false
+	b := New(appID, s).
+		WithMigrationEnabled(true).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}, nil).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "bar", Type: discordgo.ChatApplicationCommand}, func(context.Context, *discordgo.Session, *discordgo.InteractionCreate, discordgo.ApplicationCommandInteractionData) error {
+			called = true
+			return nil
+		}, WithFeature("beta")).
+		Build()
+
+	// the gated command is not migrated
+	require.NoError(t, b.migrator.Migrate(t.Context()))
+	require.Len(t, migrated, 1)
+	require.Equal(t, "foo", migrated[0].Name)
+
+	// and is unavailable if it is invoked
+	i := routertest.Command("bar", routertest.InGuild("guild"))
+	b.router.Handle(s, i)
+
+	require.False(t, called)
+	require.Equal(t, "This command is not available here yet", rec.Responses(i.Token)[0].Message.Content)
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
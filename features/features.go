@@ -0,0 +1,176 @@
+// Package features evaluates feature flags, which gate commands behind a flag so that they can be rolled out to some
+// guilds or users before everyone.
+package features
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/i18n"
+	"github.com/elliotwms/bot/interactions/router"
+	pkglog "github.com/elliotwms/bot/log"
+)
+
+// Rejected is the reason invocations of commands gated behind a disabled flag are rejected, see
+// router.Responder.Rejection.
+const Rejected = "feature"
+
+// Flag describes who a feature is enabled for. A feature is enabled if any of its conditions are met.
+type Flag struct {
+	// Name is the name of the flag
+	Name string `json:"name"`
+	// Everyone enables the feature for everyone
+	Everyone bool `json:"everyone,omitempty"`
+	// Guilds are the IDs of the guilds the feature is enabled in
+	Guilds []string `json:"guilds,omitempty"`
+	// Users are the IDs of the users the feature is enabled for, in any guild
+	Users []string `json:"users,omitempty"`
+	// Percentage is the percentage (0-100) of guilds the feature is enabled in, or of users outside of guilds. Guilds
+	// and users are bucketed by their ID, so the same guilds remain enabled as the percentage increases.
+	Percentage int `json:"percentage,omitempty"`
+}
+
+// Subject is who a flag is evaluated for.
+type Subject struct {
+	GuildID string
+	UserID  string
+}
+
+// SubjectOf returns the guild and user who triggered the interaction.
+func SubjectOf(i *discordgo.Interaction) Subject {
+	return Subject{GuildID: i.GuildID, UserID: router.UserID(i)}
+}
+
+// Enabled reports whether the feature is enabled for the subject.
+func (f Flag) Enabled(s Subject) bool {
+	switch {
+	case f.Everyone:
+		return true
+	case s.GuildID != "" && slices.Contains(f.Guilds, s.GuildID):
+		return true
+	case s.UserID != "" && slices.Contains(f.Users, s.UserID):
+		return true
+	}
+
+	id := s.GuildID
+	if id == "" {
+		id = s.UserID
+	}
+
+	return id != "" && router.Bucket(f.Name, id) < f.Percentage
+}
+
+// Global reports whether the feature may be enabled outside of its guilds, i.e. for everyone, for users or by
+// percentage, so commands gated by the flag can not be limited to its guilds.
+func (f Flag) Global() bool {
+	return f.Everyone || len(f.Users) > 0 || f.Percentage > 0
+}
+
+// Flags evaluates feature flags loaded from a store. Flags which are not in the store are disabled.
+type Flags struct {
+	store Store
+	log   *slog.Logger
+	reply func(ctx context.Context, flag string) *discordgo.InteractionResponseData
+}
+
+type Option func(*Flags)
+
+// WithLogger sets the logger used to log flag evaluations.
+func WithLogger(l *slog.Logger) Option {
+	return func(f *Flags) {
+		f.log = l
+	}
+}
+
+// WithReply sets the reply sent when a gated command is invoked while its flag is disabled. By default an ephemeral
+// message with the "features.unavailable" message from the interaction's catalog is sent.
+func WithReply(reply func(ctx context.Context, flag string) *discordgo.InteractionResponseData) Option {
+	return func(f *Flags) {
+		f.reply = reply
+	}
+}
+
+// New returns Flags which loads flags from the store.
+func New(store Store, opts ...Option) *Flags {
+	f := &Flags{
+		store: store,
+		log:   slog.New(pkglog.DiscardHandler),
+		reply: unavailable,
+	}
+
+	for _, o := range opts {
+		o(f)
+	}
+
+	return f
+}
+
+// Enabled reports whether the flag is enabled for the subject.
+func (f *Flags) Enabled(ctx context.Context, name string, s Subject) (bool, error) {
+	flag, err := f.flag(ctx, name)
+	if err != nil {
+		return false, err
+	}
+
+	return flag.Enabled(s), nil
+}
+
+// Guilds returns the guilds the flag is enabled in, and whether it may be enabled outside of them (see Flag.Global).
+func (f *Flags) Guilds(ctx context.Context, name string) ([]string, bool, error) {
+	flag, err := f.flag(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return flag.Guilds, flag.Global(), nil
+}
+
+func (f *Flags) flag(ctx context.Context, name string) (Flag, error) {
+	flag, err := f.store.Flag(ctx, name)
+	if errors.Is(err, ErrNotFound) {
+		return Flag{Name: name}, nil
+	}
+	if err != nil {
+		return Flag{}, fmt.Errorf("load flag %q: %w", name, err)
+	}
+
+	return flag, nil
+}
+
+// Middleware gates the handler behind the flag. Interactions from guilds and users the flag is not enabled for are
+// answered with the reply set by WithReply instead of reaching the handler.
+func (f *Flags) Middleware(name string) router.Middleware {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+			enabled, err := f.Enabled(ctx, name, SubjectOf(i.Interaction))
+			if err != nil {
+				return err
+			}
+
+			if enabled {
+				return next(ctx, s, i)
+			}
+
+			f.log.Debug("Feature disabled", slog.String("flag", name), slog.String("interaction", i.ID))
+
+			return router.ResponderFromContext(ctx).Reject(ctx, Rejected, f.reply(ctx, name))
+		}
+	}
+}
+
+// Require gates the command behind the flag, see Flags.Middleware.
+func Require(f *Flags, name string) router.RouteOption {
+	return router.WithMiddleware(f.Middleware(name))
+}
+
+// unavailable is the default reply sent when a flag is disabled
+func unavailable(ctx context.Context, _ string) *discordgo.InteractionResponseData {
+	return &discordgo.InteractionResponseData{
+		Content: i18n.FromContext(ctx).TDefault("features.unavailable", "This command is not available here yet"),
+		Flags:   discordgo.MessageFlagsEphemeral,
+	}
+}
@@ -0,0 +1,133 @@
+package features
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/interactions/router"
+	"github.com/elliotwms/bot/interactions/router/routertest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlag_Enabled(t *testing.T) {
+	tests := map[string]struct {
+		flag    Flag
+		subject Subject
+		want    bool
+	}{
+		"disabled":           {flag: Flag{Name: "foo"}, subject: Subject{GuildID: "guild", UserID: "user"}},
+		"everyone":           {flag: Flag{Name: "foo", Everyone: true}, subject: Subject{UserID: "user"}, want: true},
+		"guild":              {flag: Flag{Name: "foo", Guilds: []string{"guild"}}, subject: Subject{GuildID: "guild", UserID: "user"}, want: true},
+		"other guild":        {flag: Flag{Name: "foo", Guilds: []string{"other"}}, subject: Subject{GuildID: "guild", UserID: "user"}},
+		"user":               {flag: Flag{Name: "foo", Users: []string{"user"}}, subject: Subject{GuildID: "guild", UserID: "user"}, want: true},
+		"user outside guild": {flag: Flag{Name: "foo", Users: []string{"user"}}, subject: Subject{UserID: "user"}, want: true},
+		"all percent":        {flag: Flag{Name: "foo", Percentage: 100}, subject: Subject{GuildID: "guild"}, want: true},
+		"no subject":         {flag: Flag{Name: "foo", Percentage: 100}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.flag.Enabled(tt.subject))
+		})
+	}
+}
+
+func TestFlag_Enabled_Percentage(t *testing.T) {
+	half := Flag{Name: "foo", Percentage: 50}
+	more := Flag{Name: "foo", Percentage: 75}
+
+	enabled := 0
+	for n := range 1000 {
+		s := Subject{GuildID: strconv.Itoa(n)}
+
+		if half.Enabled(s) {
+			enabled++
+
+			// increasing the percentage keeps the same guilds enabled
+			require.True(t, more.Enabled(s))
+		}
+
+		// evaluation is deterministic
+		require.Equal(t, half.Enabled(s), half.Enabled(s))
+	}
+
+	require.InDelta(t, 500, enabled, 75)
+}
+
+func TestFlags_Guilds(t *testing.T) {
+	f := New(NewMemoryStore(
+		Flag{Name: "guilds", Guilds: []string{"a", "b"}},
+		Flag{Name: "users", Guilds: []string{"a"}, Users: []string{"user"}},
+	))
+
+	guilds, global, err := f.Guilds(context.Background(), "guilds")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, guilds)
+	require.False(t, global)
+
+	_, global, err = f.Guilds(context.Background(), "users")
+	require.NoError(t, err)
+	require.True(t, global)
+
+	guilds, global, err = f.Guilds(context.Background(), "missing")
+	require.NoError(t, err)
+	require.Empty(t, guilds)
+	require.False(t, global)
+}
+
+func TestFlags_StoreError(t *testing.T) {
+	f := New(storeFunc(func(context.Context, string) (Flag, error) {
+		return Flag{}, errors.New("oops")
+	}))
+
+	_, err := f.Enabled(context.Background(), "foo", Subject{GuildID: "guild"})
+	require.EqualError(t, err, `load flag "foo": oops`)
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+
+	_, err := s.Flag(context.Background(), "foo")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	s.Set(Flag{Name: "foo", Everyone: true})
+	f, err := s.Flag(context.Background(), "foo")
+	require.NoError(t, err)
+	require.True(t, f.Everyone)
+
+	s.Delete("foo")
+	_, err = s.Flag(context.Background(), "foo")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRequire(t *testing.T) {
+	store := NewMemoryStore(Flag{Name: "beta", Guilds: []string{"enabled"}})
+	f := New(store, WithReply(func(_ context.Context, flag string) *discordgo.InteractionResponseData {
+		return &discordgo.InteractionResponseData{Content: flag + " is not available"}
+	}))
+
+	called := 0
+	r := router.New()
+	r.RegisterCommand("foo", discordgo.ChatApplicationCommand, func(context.Context, *discordgo.Session, *discordgo.InteractionCreate, discordgo.ApplicationCommandInteractionData) error {
+		called++
+		return nil
+	}, Require(f, "beta"))
+
+	h := routertest.New(t, r)
+
+	res := h.Dispatch(routertest.Command("foo", routertest.InGuild("disabled")))
+	require.Zero(t, called)
+	res.RequireContent("beta is not available")
+
+	h.Dispatch(routertest.Command("foo", routertest.InGuild("enabled")))
+	require.Equal(t, 1, called)
+}
+
+type storeFunc func(ctx context.Context, name string) (Flag, error)
+
+func (f storeFunc) Flag(ctx context.Context, name string) (Flag, error) {
+	return f(ctx, name)
+}
@@ -0,0 +1,62 @@
+package features
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNotFound is returned by a Store when it has no flag with the name.
+var ErrNotFound = errors.New("flag not found")
+
+// Store loads feature flags, e.g. from a database or configuration service. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Flag returns the flag with the name, or ErrNotFound
+	Flag(ctx context.Context, name string) (Flag, error)
+}
+
+// MemoryStore stores flags in memory.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	flags map[string]Flag
+}
+
+// NewMemoryStore returns a MemoryStore with the flags.
+func NewMemoryStore(flags ...Flag) *MemoryStore {
+	s := &MemoryStore{flags: make(map[string]Flag)}
+
+	for _, f := range flags {
+		s.flags[f.Name] = f
+	}
+
+	return s
+}
+
+func (s *MemoryStore) Flag(_ context.Context, name string) (Flag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, ok := s.flags[name]
+	if !ok {
+		return Flag{}, ErrNotFound
+	}
+
+	return f, nil
+}
+
+// Set adds or replaces the flag.
+func (s *MemoryStore) Set(f Flag) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.flags[f.Name] = f
+}
+
+// Delete removes the flag with the name, if any, disabling it.
+func (s *MemoryStore) Delete(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.flags, name)
+}
@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -26,6 +27,7 @@ func (bot *Bot) httpListen() {
 	err := http.ListenAndServe(*bot.healthCheckAddr, nil)
 	if err != nil {
 		bot.log.Error("Could not serve health check endpoint", log.WithErr(err))
+		bot.report(context.Background(), "health check", err)
 		return
 	}
 }
@@ -0,0 +1,231 @@
+package bot
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/features"
+	"github.com/elliotwms/bot/interactions/router"
+)
+
+const defaultHelpPageSize = 10
+
+// HelpOption configures the help command registered by Builder.WithHelpCommand.
+type HelpOption func(*helpCommand)
+
+// WithHelpCategory groups the named command under a category in the help command's output. Commands without a
+// category are listed under "Commands".
+func WithHelpCategory(command, category string) HelpOption {
+	return func(h *helpCommand) {
+		h.categories[command] = category
+	}
+}
+
+// WithHelpPageSize sets the maximum number of commands listed on each page of the help command's output.
+func WithHelpPageSize(n int) HelpOption {
+	return func(h *helpCommand) {
+		if n > 0 {
+			h.pageSize = n
+		}
+	}
+}
+
+// helpCommand renders the bot's registered application commands as paginated embeds.
+type helpCommand struct {
+	commands   []command
+	categories map[string]string
+	pageSize   int
+	paginator  *router.Paginator
+	// features evaluates the flags of commands gated with WithFeature, which are hidden from everyone without it
+	features *features.Flags
+	// skus are the SKUs which entitle users to each command, see router.RequireSKU
+	skus map[string][]string
+}
+
+type helpEntry struct {
+	category    string
+	usage       string
+	description string
+}
+
+func newHelpCommand(opts ...HelpOption) *helpCommand {
+	h := &helpCommand{
+		categories: make(map[string]string),
+		skus:       make(map[string][]string),
+		pageSize:   defaultHelpPageSize,
+	}
+
+	for _, o := range opts {
+		o(h)
+	}
+
+	return h
+}
+
+func (h *helpCommand) definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "help",
+		Type:        discordgo.ChatApplicationCommand,
+		Description: "List the available commands",
+	}
+}
+
+// register registers the help command's paginator with the router.
+func (h *helpCommand) register(r *router.Router) {
+	h.paginator = r.NewPaginator("help", h.count, h.render)
+}
+
+func (h *helpCommand) handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, _ discordgo.ApplicationCommandInteractionData) error {
+	return h.paginator.Respond(ctx, s, i)
+}
+
+// count returns the number of pages of commands available to the user who triggered the interaction.
+func (h *helpCommand) count(ctx context.Context, i *discordgo.InteractionCreate) (int, error) {
+	entries, err := h.entries(ctx, i.Interaction)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(h.pages(entries)), nil
+}
+
+// render renders the page of commands available to the user who triggered the interaction.
+func (h *helpCommand) render(ctx context.Context, i *discordgo.InteractionCreate, page int) (*discordgo.InteractionResponseData, error) {
+	entries, err := h.entries(ctx, i.Interaction)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := h.pages(entries)
+
+	return &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{pages[min(page, len(pages)-1)]},
+		Flags:  discordgo.MessageFlagsEphemeral,
+	}, nil
+}
+
+// entries lists the commands available to the user who triggered the interaction, ordered by category and usage.
+func (h *helpCommand) entries(ctx context.Context, i *discordgo.Interaction) ([]helpEntry, error) {
+	var entries []helpEntry
+
+	for _, c := range h.commands {
+		d := c.definition
+		if commandType(d) != discordgo.ChatApplicationCommand || !canUse(d, i) {
+			continue
+		}
+
+		if skus := h.skus[d.Name]; len(skus) > 0 && !router.Entitled(i, skus...) {
+			continue
+		}
+
+		if c.feature != "" {
+			enabled, err := h.enabled(ctx, c.feature, i)
+			if err != nil {
+				return nil, err
+			}
+
+			if !enabled {
+				continue
+			}
+		}
+
+		entries = append(entries, commandEntries(h.categories[d.Name], "/"+d.Name, d.Description, d.Options)...)
+	}
+
+	slices.SortFunc(entries, func(a, b helpEntry) int {
+		return cmp.Or(
+			cmp.Compare(a.category, b.category),
+			cmp.Compare(a.usage, b.usage),
+		)
+	})
+
+	return entries, nil
+}
+
+// enabled reports whether the feature is enabled for the user who triggered the interaction. Without flags, features
+// are disabled for everyone.
+func (h *helpCommand) enabled(ctx context.Context, feature string, i *discordgo.Interaction) (bool, error) {
+	if h.features == nil {
+		return false, nil
+	}
+
+	return h.features.Enabled(ctx, feature, features.SubjectOf(i))
+}
+
+// pages splits the entries into embeds, starting a new page for each category.
+func (h *helpCommand) pages(entries []helpEntry) []*discordgo.MessageEmbed {
+	var pages []*discordgo.MessageEmbed
+
+	for n, e := range entries {
+		if n == 0 || e.category != entries[n-1].category || len(pages[len(pages)-1].Fields) == h.pageSize {
+			pages = append(pages, &discordgo.MessageEmbed{Title: cmp.Or(e.category, "Commands")})
+		}
+
+		p := pages[len(pages)-1]
+		p.Fields = append(p.Fields, &discordgo.MessageEmbedField{
+			Name:  e.usage,
+			Value: cmp.Or(e.description, "No description"),
+		})
+	}
+
+	if len(pages) == 0 {
+		return []*discordgo.MessageEmbed{{Title: "Commands", Description: "There are no commands available"}}
+	}
+
+	for n, p := range pages {
+		p.Footer = &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Page %d of %d", n+1, len(pages))}
+	}
+
+	return pages
+}
+
+// commandEntries returns an entry for the command, or one for each of its subcommands.
+func commandEntries(category, usage, description string, options []*discordgo.ApplicationCommandOption) []helpEntry {
+	var entries []helpEntry
+	var args []string
+
+	for _, o := range options {
+		switch o.Type {
+		case discordgo.ApplicationCommandOptionSubCommand, discordgo.ApplicationCommandOptionSubCommandGroup:
+			entries = append(entries, commandEntries(category, usage+" "+o.Name, o.Description, o.Options)...)
+		default:
+			if o.Required {
+				args = append(args, "<"+o.Name+">")
+			} else {
+				args = append(args, "["+o.Name+"]")
+			}
+		}
+	}
+
+	if len(entries) > 0 {
+		return entries
+	}
+
+	return []helpEntry{{
+		category:    category,
+		usage:       strings.Join(append([]string{usage}, args...), " "),
+		description: description,
+	}}
+}
+
+// canUse reports whether the user who triggered the interaction is permitted to use the command.
+func canUse(c *discordgo.ApplicationCommand, i *discordgo.Interaction) bool {
+	if c.Contexts != nil && !slices.Contains(*c.Contexts, i.Context) {
+		return false
+	}
+
+	if i.Member == nil || c.DefaultMemberPermissions == nil || i.Member.Permissions&discordgo.PermissionAdministrator != 0 {
+		return true
+	}
+
+	// no permissions restricts the command to administrators
+	if *c.DefaultMemberPermissions == 0 {
+		return false
+	}
+
+	return i.Member.Permissions&*c.DefaultMemberPermissions == *c.DefaultMemberPermissions
+}
@@ -0,0 +1,199 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/features"
+	"github.com/elliotwms/bot/interactions/router"
+	"github.com/elliotwms/bot/interactions/router/routertest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelp_Entries(t *testing.T) {
+	manageGuild := int64(discordgo.PermissionManageGuild)
+	adminOnly := int64(0)
+
+	h := newHelpCommand(WithHelpCategory("ban", "Moderation"))
+	h.commands = []command{
+		{definition: &discordgo.ApplicationCommand{Name: "ping", Type: discordgo.ChatApplicationCommand, Description: "Ping the bot"}},
+		{definition: &discordgo.ApplicationCommand{Name: "ban", Type: discordgo.ChatApplicationCommand, Description: "Ban a user", DefaultMemberPermissions: &manageGuild, Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionUser, Name: "user", Required: true},
+			{Type: discordgo.ApplicationCommandOptionString, Name: "reason"},
+		}}},
+		{definition: &discordgo.ApplicationCommand{Name: "config", Type: discordgo.ChatApplicationCommand, Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "get", Description: "Get a value"},
+			{Type: discordgo.ApplicationCommandOptionSubCommandGroup, Name: "role", Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "add", Description: "Add a role"},
+			}},
+		}}},
+		{definition: &discordgo.ApplicationCommand{Name: "Report", Type: discordgo.MessageApplicationCommand}},
+		{definition: &discordgo.ApplicationCommand{Name: "untyped", Description: "Defaults to a chat input command"}},
+		{definition: &discordgo.ApplicationCommand{Name: "purge", Type: discordgo.ChatApplicationCommand, Description: "Purge messages", DefaultMemberPermissions: &adminOnly}},
+	}
+
+	t.Run("member with permissions", func(t *testing.T) {
+		entries, err := h.entries(context.Background(), &discordgo.Interaction{Member: &discordgo.Member{Permissions: discordgo.PermissionManageGuild}})
+		require.NoError(t, err)
+
+		require.Equal(t, []helpEntry{
+			{usage: "/config get", description: "Get a value"},
+			{usage: "/config role add", description: "Add a role"},
+			{usage: "/ping", description: "Ping the bot"},
+			{usage: "/untyped", description: "Defaults to a chat input command"},
+			{category: "Moderation", usage: "/ban <user> [reason]", description: "Ban a user"},
+		}, entries)
+	})
+
+	t.Run("member without permissions", func(t *testing.T) {
+		entries, err := h.entries(context.Background(), &discordgo.Interaction{Member: &discordgo.Member{}})
+		require.NoError(t, err)
+
+		require.Len(t, entries, 4)
+		require.NotContains(t, entries, helpEntry{category: "Moderation", usage: "/ban <user> [reason]", description: "Ban a user"})
+	})
+
+	t.Run("administrator", func(t *testing.T) {
+		entries, err := h.entries(context.Background(), &discordgo.Interaction{Member: &discordgo.Member{Permissions: discordgo.PermissionAdministrator}})
+		require.NoError(t, err)
+
+		require.Len(t, entries, 6)
+		require.Contains(t, entries, helpEntry{usage: "/purge", description: "Purge messages"})
+	})
+}
+
+func TestHelp_Pages(t *testing.T) {
+	h := newHelpCommand(WithHelpPageSize(2))
+
+	pages := h.pages([]helpEntry{
+		{usage: "/a"},
+		{usage: "/b"},
+		{usage: "/c"},
+		{category: "Other", usage: "/d", description: "D"},
+	})
+
+	require.Len(t, pages, 3)
+	require.Equal(t, "Commands", pages[0].Title)
+	require.Len(t, pages[0].Fields, 2)
+	require.Len(t, pages[1].Fields, 1)
+	require.Equal(t, "Other", pages[2].Title)
+	require.Equal(t, "Page 3 of 3", pages[2].Footer.Text)
+}
+
+func TestHelp_Pages_Empty(t *testing.T) {
+	pages := newHelpCommand().pages(nil)
+
+	require.Len(t, pages, 1)
+}
+
+func TestBuilder_WithHelpCommand(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	builder := New(appID, s).
+		WithMigrationEnabled(true).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}, nil).
+		WithHelpCommand()
+
+	b := builder.Build()
+
+	require.Equal(t, []router.Route{
+		{Type: discordgo.InteractionApplicationCommand, Name: "foo", CommandType: discordgo.ChatApplicationCommand},
+		{Type: discordgo.InteractionApplicationCommand, Name: "help", CommandType: discordgo.ChatApplicationCommand},
+		{Type: discordgo.InteractionMessageComponent, Name: "paginator:help"},
+	}, b.router.Routes())
+
+	entries, err := builder.help.entries(context.Background(), &discordgo.Interaction{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+}
+
+func TestHelp_Entries_Gated(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	builder := New(appID, s).
+		WithFeatures(features.New(features.NewMemoryStore(features.Flag{Name: "beta", Guilds: []string{"beta-guild"}}))).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}, nil).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "bar", Type: discordgo.ChatApplicationCommand}, nil, WithFeature("beta")).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "baz", Type: discordgo.ChatApplicationCommand}, nil, WithRouteOptions(router.RequireSKU("sku"))).
+		WithHelpCommand()
+
+	builder.Build()
+
+	t.Run("disabled", func(t *testing.T) {
+		entries, err := builder.help.entries(context.Background(), &discordgo.Interaction{GuildID: "guild"})
+		require.NoError(t, err)
+
+		require.Equal(t, []helpEntry{{usage: "/foo"}, {usage: "/help", description: "List the available commands"}}, entries)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		entries, err := builder.help.entries(context.Background(), &discordgo.Interaction{
+			GuildID:      "beta-guild",
+			Entitlements: []*discordgo.Entitlement{{SKUID: "sku"}},
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, []helpEntry{{usage: "/bar"}, {usage: "/baz"}, {usage: "/foo"}, {usage: "/help", description: "List the available commands"}}, entries)
+	})
+}
+
+func TestHelp_DeferredResponse(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	b := New("app", s).
+		WithRouter(router.New(router.WithDeferredResponse(true))).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}, nil).
+		WithHelpCommand().
+		Build()
+
+	res := routertest.New(t, b.router).Dispatch(routertest.Command("help"))
+
+	res.RequireResponse(discordgo.InteractionResponseDeferredChannelMessageWithSource)
+	res.RequireEdits(1)
+	require.Len(t, res.Responses(), 1)
+	require.Equal(t, "/foo", res.Original().Embeds[0].Fields[0].Name)
+	require.NotEmpty(t, nextButton(t, res.Original()))
+}
+
+func TestHelp_Paginated(t *testing.T) {
+	s, _ := discordgo.New("token")
+
+	b := New("app", s).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "bar", Type: discordgo.ChatApplicationCommand}, nil).
+		WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo", Type: discordgo.ChatApplicationCommand}, nil).
+		WithHelpCommand(WithHelpPageSize(2)).
+		Build()
+
+	h := routertest.New(t, b.router)
+	user := &discordgo.User{ID: "user"}
+
+	res := h.Dispatch(routertest.Command("help", routertest.ByUser(user)))
+	res.RequireEphemeral()
+	require.Equal(t, "Page 1 of 2", res.Original().Embeds[0].Footer.Text)
+
+	next := nextButton(t, res.Original())
+
+	other := h.Dispatch(routertest.Button(next, routertest.ByUser(&discordgo.User{ID: "other"})))
+	other.RequireContent("These buttons can only be used by the user who triggered them")
+
+	clicked := h.Dispatch(routertest.Button(next, routertest.ByUser(user)))
+	page := clicked.RequireResponse(discordgo.InteractionResponseUpdateMessage)
+	require.Equal(t, "Page 2 of 2", page.Message.Embeds[0].Footer.Text)
+	require.Equal(t, "/help", page.Message.Embeds[0].Fields[0].Name)
+}
+
+// nextButton returns the custom ID of the message's Next button
+func nextButton(t *testing.T, m *discordgo.Message) string {
+	for _, row := range m.Components {
+		for _, c := range row.(*discordgo.ActionsRow).Components {
+			if b, ok := c.(*discordgo.Button); ok && b.Label == "Next" {
+				return b.CustomID
+			}
+		}
+	}
+
+	require.FailNow(t, "the message should have a Next button")
+
+	return ""
+}
@@ -0,0 +1,255 @@
+// Package i18n provides message catalogs for localizing responses and application commands.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog holds the messages for each locale, keyed by message key. Messages are either a single string or a set of
+// plural forms (zero, one, two, few, many, other).
+type Catalog struct {
+	mu       sync.RWMutex
+	fallback discordgo.Locale
+	messages map[discordgo.Locale]map[string]message
+}
+
+// message holds a message's plural forms, where messages without plural forms only have other set
+type message map[string]string
+
+// New creates an empty Catalog which falls back to the fallback locale when a message is not found.
+func New(fallback discordgo.Locale) *Catalog {
+	return &Catalog{
+		fallback: fallback,
+		messages: make(map[discordgo.Locale]map[string]message),
+	}
+}
+
+// Load creates a Catalog from the JSON and YAML files in fsys (e.g. an embed.FS), named by locale (e.g. "en-US.json",
+// "pt-BR.yaml"). Files may contain nested objects, which are flattened into "." separated keys.
+func Load(fsys fs.FS, fallback discordgo.Locale) (*Catalog, error) {
+	c := New(fallback)
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		ext := path.Ext(e.Name())
+
+		var unmarshal func([]byte, any) error
+		switch ext {
+		case ".json":
+			unmarshal = json.Unmarshal
+		case ".yaml", ".yml":
+			unmarshal = yaml.Unmarshal
+		default:
+			continue
+		}
+
+		bs, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		var v map[string]any
+		if err := unmarshal(bs, &v); err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", e.Name(), err)
+		}
+
+		messages := make(map[string]message)
+		if err := flatten(messages, "", v); err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", e.Name(), err)
+		}
+
+		c.add(discordgo.Locale(strings.TrimSuffix(e.Name(), ext)), messages)
+	}
+
+	return c, nil
+}
+
+// Add adds messages for the locale, replacing any existing messages with the same keys.
+func (c *Catalog) Add(locale discordgo.Locale, messages map[string]string) {
+	m := make(map[string]message, len(messages))
+	for k, v := range messages {
+		m[k] = message{"other": v}
+	}
+
+	c.add(locale, m)
+}
+
+// AddPlural adds a message with plural forms for the locale, keyed by plural category (zero, one, two, few, many,
+// other).
+func (c *Catalog) AddPlural(locale discordgo.Locale, key string, forms map[string]string) {
+	c.add(locale, map[string]message{key: forms})
+}
+
+func (c *Catalog) add(locale discordgo.Locale, messages map[string]message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.messages[locale] == nil {
+		c.messages[locale] = make(map[string]message)
+	}
+
+	for k, v := range messages {
+		c.messages[locale][k] = v
+	}
+}
+
+func (c *Catalog) lookup(locale discordgo.Locale, key string) (message, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	m, ok := c.messages[locale][key]
+
+	return m, ok
+}
+
+// Translator returns a Translator for the locales in order of preference, e.g. the interaction's locale followed by
+// the guild's locale. Each locale falls back to its base language (e.g. "pt" for "pt-BR") before the next locale,
+// and the catalog's fallback locale is tried last.
+func (c *Catalog) Translator(locales ...discordgo.Locale) *Translator {
+	var chain []discordgo.Locale
+
+	for _, l := range append(locales, c.fallback) {
+		for _, l := range []discordgo.Locale{l, base(l)} {
+			if l != "" && !slices.Contains(chain, l) {
+				chain = append(chain, l)
+			}
+		}
+	}
+
+	return &Translator{catalog: c, locales: chain}
+}
+
+// LocalizeCommand sets the command's NameLocalizations and DescriptionLocalizations, including those of its options
+// and choices, from the catalog. Messages are keyed by the command's path, e.g. "commands.<command>.name",
+// "commands.<command>.description", "commands.<command>.options.<option>.description" and
+// "commands.<command>.options.<option>.choices.<choice>". Only locales supported by Discord are used.
+func (c *Catalog) LocalizeCommand(cmd *discordgo.ApplicationCommand) {
+	prefix := "commands." + cmd.Name
+
+	if l := c.localizations(prefix+".name", cmd.NameLocalizations); l != nil {
+		cmd.NameLocalizations = &l
+	}
+
+	if l := c.localizations(prefix+".description", cmd.DescriptionLocalizations); l != nil {
+		cmd.DescriptionLocalizations = &l
+	}
+
+	c.localizeOptions(prefix, cmd.Options)
+}
+
+func (c *Catalog) localizeOptions(prefix string, options []*discordgo.ApplicationCommandOption) {
+	for _, o := range options {
+		p := prefix + ".options." + o.Name
+
+		o.NameLocalizations = c.localizations(p+".name", &o.NameLocalizations)
+		o.DescriptionLocalizations = c.localizations(p+".description", &o.DescriptionLocalizations)
+
+		for _, choice := range o.Choices {
+			choice.NameLocalizations = c.localizations(p+".choices."+choice.Name, &choice.NameLocalizations)
+		}
+
+		c.localizeOptions(p, o.Options)
+	}
+}
+
+// localizations returns the existing localizations with the key's message added for each locale supported by Discord
+func (c *Catalog) localizations(key string, existing *map[discordgo.Locale]string) map[discordgo.Locale]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var l map[discordgo.Locale]string
+	if existing != nil {
+		l = *existing
+	}
+
+	for locale, messages := range c.messages {
+		if _, ok := discordgo.Locales[locale]; !ok || locale == discordgo.Unknown {
+			continue
+		}
+
+		m, ok := messages[key]
+		if !ok {
+			continue
+		}
+
+		if l == nil {
+			l = make(map[discordgo.Locale]string)
+		}
+
+		l[locale] = m["other"]
+	}
+
+	return l
+}
+
+// flatten adds the messages in v to messages, joining nested keys with "."
+func flatten(messages map[string]message, prefix string, v map[string]any) error {
+	for k, v := range v {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+
+		switch v := v.(type) {
+		case string:
+			messages[k] = message{"other": v}
+		case map[string]any:
+			if m, ok := pluralForms(v); ok {
+				messages[k] = m
+				continue
+			}
+
+			if err := flatten(messages, k, v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unexpected value for %q: %v", k, v)
+		}
+	}
+
+	return nil
+}
+
+// pluralForms returns the message's plural forms if every key in v is a plural category
+func pluralForms(v map[string]any) (message, bool) {
+	if _, ok := v["other"]; !ok {
+		return nil, false
+	}
+
+	m := make(message, len(v))
+	for k, v := range v {
+		s, ok := v.(string)
+		if !ok || !slices.Contains(pluralCategories, k) {
+			return nil, false
+		}
+
+		m[k] = s
+	}
+
+	return m, true
+}
+
+// base returns the base language of the locale, e.g. "pt" for "pt-BR"
+func base(l discordgo.Locale) discordgo.Locale {
+	if n := strings.Index(string(l), "-"); n > 0 {
+		return l[:n]
+	}
+
+	return l
+}
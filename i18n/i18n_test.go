@@ -0,0 +1,117 @@
+package i18n
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+)
+
+func load(t *testing.T) *Catalog {
+	c, err := Load(os.DirFS("testdata"), discordgo.EnglishUS)
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestTranslator_T(t *testing.T) {
+	c := load(t)
+
+	tests := map[string]struct {
+		locales  []discordgo.Locale
+		key      string
+		expected string
+	}{
+		"fallback locale":        {nil, "greeting", "Hello, Bob!"},
+		"base language":          {[]discordgo.Locale{discordgo.PortugueseBR}, "greeting", "Olá, Bob!"},
+		"second locale":          {[]discordgo.Locale{discordgo.Japanese, discordgo.PortugueseBR}, "farewell", "Tchau!"},
+		"unknown locale":         {[]discordgo.Locale{discordgo.Japanese}, "greeting", "Hello, Bob!"},
+		"missing key":            {[]discordgo.Locale{discordgo.PortugueseBR}, "missing", "missing"},
+		"nested key":             {[]discordgo.Locale{discordgo.PortugueseBR}, "commands.ping.name", "pingue"},
+		"nested key in fallback": {[]discordgo.Locale{discordgo.Japanese}, "commands.ping.description", "Ping the bot"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.expected, c.Translator(tt.locales...).T(tt.key, "name", "Bob"))
+		})
+	}
+}
+
+func TestTranslator_TDefault(t *testing.T) {
+	c := load(t)
+
+	require.Equal(t, "Olá, Bob!", c.Translator(discordgo.PortugueseBR).TDefault("greeting", "Hi, {name}", "name", "Bob"))
+	require.Equal(t, "Hi, Bob", c.Translator(discordgo.PortugueseBR).TDefault("missing", "Hi, {name}", "name", "Bob"))
+	require.Equal(t, "Hi, Bob", FromContext(context.Background()).TDefault("greeting", "Hi, {name}", "name", "Bob"))
+}
+
+func TestTranslator_N(t *testing.T) {
+	c := load(t)
+
+	tests := map[string]struct {
+		locale   discordgo.Locale
+		n        int
+		expected string
+	}{
+		"en zero":      {discordgo.EnglishUS, 0, "No items"},
+		"en one":       {discordgo.EnglishUS, 1, "1 item"},
+		"en other":     {discordgo.EnglishUS, 2, "2 items"},
+		"pt-BR zero":   {discordgo.PortugueseBR, 0, "0 item"},
+		"pt-BR other":  {discordgo.PortugueseBR, 2, "2 itens"},
+		"ru one":       {discordgo.Russian, 21, "21 предмет"},
+		"ru few":       {discordgo.Russian, 3, "3 предмета"},
+		"ru many":      {discordgo.Russian, 11, "11 предметов"},
+		"ja fallback":  {discordgo.Japanese, 1, "1 item"},
+		"en-GB plural": {discordgo.EnglishGB, 5, "5 items"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.expected, c.Translator(tt.locale).N("items", tt.n))
+		})
+	}
+}
+
+func TestCatalog_Add(t *testing.T) {
+	c := New(discordgo.EnglishUS)
+
+	c.Add(discordgo.German, map[string]string{"greeting": "Hallo, {name}!"})
+	c.AddPlural(discordgo.German, "items", map[string]string{"one": "{count} Artikel", "other": "{count} Artikel"})
+
+	require.Equal(t, "Hallo, Bob!", c.Translator(discordgo.German).T("greeting", "name", "Bob"))
+	require.Equal(t, "2 Artikel", c.Translator(discordgo.German).N("items", 2))
+}
+
+func TestCatalog_LocalizeCommand(t *testing.T) {
+	c := load(t)
+
+	cmd := &discordgo.ApplicationCommand{
+		Name:        "ping",
+		Description: "Ping the bot",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "target",
+				Description: "The target",
+				Choices:     []*discordgo.ApplicationCommandOptionChoice{{Name: "everyone", Value: "everyone"}},
+			},
+		},
+	}
+
+	c.LocalizeCommand(cmd)
+
+	require.Equal(t, &map[discordgo.Locale]string{discordgo.PortugueseBR: "pingue"}, cmd.NameLocalizations)
+	require.Equal(t, &map[discordgo.Locale]string{discordgo.EnglishUS: "Ping the bot", discordgo.PortugueseBR: "Pingar o bot"}, cmd.DescriptionLocalizations)
+	require.Nil(t, cmd.Options[0].NameLocalizations)
+	require.Equal(t, map[discordgo.Locale]string{discordgo.PortugueseBR: "O alvo"}, cmd.Options[0].DescriptionLocalizations)
+	require.Equal(t, map[discordgo.Locale]string{discordgo.PortugueseBR: "todos"}, cmd.Options[0].Choices[0].NameLocalizations)
+}
+
+func TestFromContext(t *testing.T) {
+	tr := load(t).Translator(discordgo.PortugueseBR)
+
+	require.Equal(t, tr, FromContext(NewContext(context.Background(), tr)))
+	require.Equal(t, "greeting", FromContext(context.Background()).T("greeting"))
+}
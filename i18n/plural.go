@@ -0,0 +1,75 @@
+package i18n
+
+import "github.com/bwmarrin/discordgo"
+
+var pluralCategories = []string{"zero", "one", "two", "few", "many", "other"}
+
+// pluralCategory returns the CLDR plural category of n for the locale's language. Only the cardinal rules for integers
+// of the languages supported by Discord are implemented.
+func pluralCategory(locale discordgo.Locale, n int) string {
+	switch base(locale) {
+	case "ja", "ko", "zh", "th", "vi":
+		return "other"
+	case "fr", "pt", "hi":
+		if n == 0 || n == 1 {
+			return "one"
+		}
+	case "ru", "uk":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case few(n):
+			return "few"
+		default:
+			return "many"
+		}
+	case "hr":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case few(n):
+			return "few"
+		}
+	case "pl":
+		switch {
+		case n == 1:
+			return "one"
+		case few(n):
+			return "few"
+		default:
+			return "many"
+		}
+	case "cs":
+		switch {
+		case n == 1:
+			return "one"
+		case n >= 2 && n <= 4:
+			return "few"
+		}
+	case "lt":
+		switch {
+		case n%10 == 1 && (n%100 < 11 || n%100 > 19):
+			return "one"
+		case n%10 >= 2 && (n%100 < 11 || n%100 > 19):
+			return "few"
+		}
+	case "ro":
+		switch {
+		case n == 1:
+			return "one"
+		case n == 0 || n%100 >= 2 && n%100 <= 19:
+			return "few"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+	}
+
+	return "other"
+}
+
+// few reports whether n ends in 2-4, excluding 12-14, as used by several Slavic languages
+func few(n int) bool {
+	return n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14)
+}
@@ -0,0 +1,112 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Translator translates messages from a Catalog using a chain of locales.
+type Translator struct {
+	catalog *Catalog
+	locales []discordgo.Locale
+}
+
+// Locale returns the most preferred locale of the translator.
+func (t *Translator) Locale() discordgo.Locale {
+	if t == nil || len(t.locales) == 0 {
+		return discordgo.Unknown
+	}
+
+	return t.locales[0]
+}
+
+// T returns the message for the key in the first locale which has it, or the key if no locale does. Placeholders in
+// the message (e.g. "{name}") are replaced with the args, given as key-value pairs (e.g. T("greeting", "name", "Bob")).
+func (t *Translator) T(key string, args ...any) string {
+	m, locale, ok := t.lookup(key)
+	if !ok {
+		return key
+	}
+
+	return format(m.form(locale, -1), args)
+}
+
+// TDefault returns the message for the key like T, or the formatted default message if no locale has it. This allows
+// packages to provide messages which a catalog can optionally translate.
+func (t *Translator) TDefault(key, def string, args ...any) string {
+	m, locale, ok := t.lookup(key)
+	if !ok {
+		return format(def, args)
+	}
+
+	return format(m.form(locale, -1), args)
+}
+
+// N returns the plural form of the message for the key for count n, following the plural rules of the locale the
+// message was found in. The "{count}" placeholder is replaced with n, alongside any args.
+func (t *Translator) N(key string, n int, args ...any) string {
+	m, locale, ok := t.lookup(key)
+	if !ok {
+		return key
+	}
+
+	return format(m.form(locale, n), append([]any{"count", n}, args...))
+}
+
+func (t *Translator) lookup(key string) (message, discordgo.Locale, bool) {
+	if t == nil {
+		return nil, "", false
+	}
+
+	for _, l := range t.locales {
+		if m, ok := t.catalog.lookup(l, key); ok {
+			return m, l, true
+		}
+	}
+
+	return nil, "", false
+}
+
+// form returns the plural form of the message for n, or the message's default form if n is negative
+func (m message) form(locale discordgo.Locale, n int) string {
+	if n < 0 {
+		return m["other"]
+	}
+
+	// an explicit zero form takes precedence over the locale's plural rules
+	if s, ok := m["zero"]; ok && n == 0 {
+		return s
+	}
+
+	if s, ok := m[pluralCategory(locale, n)]; ok {
+		return s
+	}
+
+	return m["other"]
+}
+
+func format(s string, args []any) string {
+	for i := 0; i+1 < len(args); i += 2 {
+		s = strings.ReplaceAll(s, fmt.Sprintf("{%v}", args[i]), fmt.Sprint(args[i+1]))
+	}
+
+	return s
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the translator.
+func NewContext(ctx context.Context, t *Translator) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the translator carried by ctx. If there is none, the returned translator returns keys
+// untranslated.
+func FromContext(ctx context.Context) *Translator {
+	t, _ := ctx.Value(contextKey{}).(*Translator)
+
+	return t
+}
@@ -0,0 +1,193 @@
+// Package audit records an audit trail of application command invocations, for moderation accountability.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/interactions/router"
+	pkglog "github.com/elliotwms/bot/log"
+)
+
+// Outcome is the result of handling an invocation.
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	// OutcomeRejected is the outcome of invocations the router or middleware rejected before the handler was called,
+	// e.g. because the user is not entitled to the command (see router.Responder.Reject)
+	OutcomeRejected Outcome = "rejected"
+	OutcomeError    Outcome = "error"
+	OutcomePanic    Outcome = "panic"
+)
+
+// Redacted replaces the values of redacted options.
+const Redacted = "[REDACTED]"
+
+// Record describes an application command invocation and its outcome.
+type Record struct {
+	Time          time.Time `json:"time"`
+	InteractionID string    `json:"interaction_id"`
+	UserID        string    `json:"user_id"`
+	GuildID       string    `json:"guild_id,omitempty"`
+	ChannelID     string    `json:"channel_id,omitempty"`
+	// Command is the path of the invoked command, including any subcommand group and subcommand (e.g. "mod ban user")
+	Command string `json:"command"`
+	// Variant is the variant of the command which handled the invocation, if it has variants (see router.WithVariants)
+	Variant string `json:"variant,omitempty"`
+	// TargetID is the ID of the user or message a context menu command was invoked on
+	TargetID string `json:"target_id,omitempty"`
+	// Options are the values of the invoked command's options by name, after redaction
+	Options map[string]any `json:"options,omitempty"`
+	Outcome Outcome        `json:"outcome"`
+	// Reason is why the invocation was rejected, if it was (e.g. router.RejectedEntitlement)
+	Reason   string        `json:"reason,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Sink stores audit records. Implementations must be safe for concurrent use.
+type Sink interface {
+	Write(ctx context.Context, r Record) error
+}
+
+// Redactor returns the value to record for the option of the command, e.g. to mask sensitive values.
+type Redactor func(command, option string, value any) any
+
+type config struct {
+	log       *slog.Logger
+	redactors []Redactor
+	now       func() time.Time
+}
+
+type Option func(*config)
+
+// WithLogger sets the logger used to log records which could not be written to the sink.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *config) {
+		c.log = l
+	}
+}
+
+// WithRedactor passes the value of every option through the redactor before it is recorded. Redactors are applied in
+// the order they are added.
+func WithRedactor(r Redactor) Option {
+	return func(c *config) {
+		c.redactors = append(c.redactors, r)
+	}
+}
+
+// RedactOptions replaces the values of the named options of any command with Redacted.
+func RedactOptions(names ...string) Option {
+	return WithRedactor(func(_, option string, value any) any {
+		if slices.Contains(names, option) {
+			return Redacted
+		}
+
+		return value
+	})
+}
+
+// Middleware records every application command invocation handled by the router to the sink once the handler has
+// returned. Other interactions are not recorded. Panics are recorded before they are propagated to the router.
+func Middleware(sink Sink, opts ...Option) router.Middleware {
+	c := &config{
+		log: slog.New(pkglog.DiscardHandler),
+		now: time.Now,
+	}
+
+	for _, o := range opts {
+		o(c)
+	}
+
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) (err error) {
+			if i.Type != discordgo.InteractionApplicationCommand {
+				return next(ctx, s, i)
+			}
+
+			start := c.now()
+
+			defer func() {
+				r := c.record(i, start)
+				r.Variant = router.VariantFromContext(ctx)
+
+				if v := recover(); v != nil {
+					r.Outcome, r.Error = OutcomePanic, fmt.Sprint(v)
+					c.write(ctx, sink, r)
+					panic(v)
+				}
+
+				switch rs := router.ResponderFromContext(ctx); {
+				case err != nil:
+					r.Outcome, r.Error = OutcomeError, err.Error()
+				case rs != nil && rs.Rejection() != "":
+					r.Outcome, r.Reason = OutcomeRejected, rs.Rejection()
+				}
+
+				c.write(ctx, sink, r)
+			}()
+
+			return next(ctx, s, i)
+		}
+	}
+}
+
+func (c *config) write(ctx context.Context, sink Sink, r Record) {
+	// the record should be written even if the interaction's context is done
+	if err := sink.Write(context.WithoutCancel(ctx), r); err != nil {
+		c.log.Error("Failed to write audit record", pkglog.WithErr(err), slog.String("interaction", r.InteractionID))
+	}
+}
+
+// record describes the invocation, assuming it succeeded
+func (c *config) record(i *discordgo.InteractionCreate, start time.Time) Record {
+	data := i.ApplicationCommandData()
+	path, options := commandPath(data)
+
+	r := Record{
+		Time:          start,
+		InteractionID: i.ID,
+		UserID:        router.UserID(i.Interaction),
+		GuildID:       i.GuildID,
+		ChannelID:     i.ChannelID,
+		Command:       strings.Join(path, " "),
+		TargetID:      data.TargetID,
+		Outcome:       OutcomeSuccess,
+		Duration:      c.now().Sub(start),
+	}
+
+	if len(options) > 0 {
+		r.Options = make(map[string]any, len(options))
+	}
+
+	for _, o := range options {
+		value := o.Value
+		for _, redact := range c.redactors {
+			value = redact(r.Command, o.Name, value)
+		}
+
+		r.Options[o.Name] = value
+	}
+
+	return r
+}
+
+// commandPath returns the names of the command and any invoked subcommand group and subcommand, and the invoked
+// subcommand's options
+func commandPath(data discordgo.ApplicationCommandInteractionData) ([]string, []*discordgo.ApplicationCommandInteractionDataOption) {
+	path := []string{data.Name}
+	options := data.Options
+
+	for len(options) == 1 && (options[0].Type == discordgo.ApplicationCommandOptionSubCommandGroup || options[0].Type == discordgo.ApplicationCommandOptionSubCommand) {
+		path = append(path, options[0].Name)
+		options = options[0].Options
+	}
+
+	return path, options
+}
@@ -0,0 +1,201 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/features"
+	"github.com/elliotwms/bot/interactions/router"
+	"github.com/elliotwms/bot/interactions/router/routertest"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink records the records written to it
+type recordingSink struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+func (s *recordingSink) Write(_ context.Context, r Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records = append(s.records, r)
+
+	return nil
+}
+
+func command(data discordgo.ApplicationCommandInteractionData) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:        "interaction",
+		Type:      discordgo.InteractionApplicationCommand,
+		GuildID:   "guild",
+		ChannelID: "channel",
+		Member:    &discordgo.Member{User: &discordgo.User{ID: "user"}},
+		Data:      data,
+	}}
+}
+
+func TestMiddleware(t *testing.T) {
+	sink := &recordingSink{}
+	handler := Middleware(sink, RedactOptions("reason"))(func(context.Context, *discordgo.Session, *discordgo.InteractionCreate) error {
+		return nil
+	})
+
+	err := handler(context.Background(), nil, command(discordgo.ApplicationCommandInteractionData{
+		Name: "mod",
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{{
+			Name: "ban",
+			Type: discordgo.ApplicationCommandOptionSubCommand,
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "user", Type: discordgo.ApplicationCommandOptionUser, Value: "target"},
+				{Name: "reason", Type: discordgo.ApplicationCommandOptionString, Value: "secret"},
+			},
+		}},
+	}))
+	require.NoError(t, err)
+
+	require.Len(t, sink.records, 1)
+	r := sink.records[0]
+	require.Equal(t, "interaction", r.InteractionID)
+	require.Equal(t, "user", r.UserID)
+	require.Equal(t, "guild", r.GuildID)
+	require.Equal(t, "channel", r.ChannelID)
+	require.Equal(t, "mod ban", r.Command)
+	require.Equal(t, map[string]any{"user": "target", "reason": Redacted}, r.Options)
+	require.Equal(t, OutcomeSuccess, r.Outcome)
+	require.False(t, r.Time.IsZero())
+}
+
+func TestMiddleware_Error(t *testing.T) {
+	sink := &recordingSink{}
+	handler := Middleware(sink)(func(context.Context, *discordgo.Session, *discordgo.InteractionCreate) error {
+		return errors.New("oops")
+	})
+
+	err := handler(context.Background(), nil, command(discordgo.ApplicationCommandInteractionData{Name: "foo"}))
+	require.EqualError(t, err, "oops")
+
+	require.Len(t, sink.records, 1)
+	require.Equal(t, OutcomeError, sink.records[0].Outcome)
+	require.Equal(t, "oops", sink.records[0].Error)
+	require.Nil(t, sink.records[0].Options)
+}
+
+func TestMiddleware_Panic(t *testing.T) {
+	sink := &recordingSink{}
+	handler := Middleware(sink)(func(context.Context, *discordgo.Session, *discordgo.InteractionCreate) error {
+		panic("oops")
+	})
+
+	require.PanicsWithValue(t, "oops", func() {
+		_ = handler(context.Background(), nil, command(discordgo.ApplicationCommandInteractionData{Name: "foo"}))
+	})
+
+	require.Len(t, sink.records, 1)
+	require.Equal(t, OutcomePanic, sink.records[0].Outcome)
+	require.Equal(t, "oops", sink.records[0].Error)
+}
+
+func TestMiddleware_Rejected(t *testing.T) {
+	tests := map[string]struct {
+		opts   []router.RouteOption
+		reason string
+	}{
+		"context":     {opts: []router.RouteOption{router.WithContexts(discordgo.InteractionContextBotDM)}, reason: router.RejectedContext},
+		"entitlement": {opts: []router.RouteOption{router.RequireSKU("sku")}, reason: router.RejectedEntitlement},
+		"validation":  {opts: []router.RouteOption{router.WithRules(router.OneOf("colour", "red"))}, reason: router.RejectedValidation},
+		"feature":     {opts: []router.RouteOption{features.Require(features.New(features.NewMemoryStore()), "beta")}, reason: features.Rejected},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sink := &recordingSink{}
+			called := false
+
+			r := router.New()
+			r.Use(Middleware(sink))
+			r.RegisterCommand("foo", discordgo.ChatApplicationCommand, func(context.Context, *discordgo.Session, *discordgo.InteractionCreate, discordgo.ApplicationCommandInteractionData) error {
+				called = true
+				return nil
+			}, tt.opts...)
+
+			res := routertest.New(t, r).Dispatch(routertest.Command("foo",
+				routertest.InGuild("guild"),
+				routertest.Options(routertest.StringOption("colour", "blue")),
+			))
+
+			res.RequireEphemeral()
+			require.False(t, called)
+			require.Len(t, sink.records, 1)
+			require.Equal(t, OutcomeRejected, sink.records[0].Outcome)
+			require.Equal(t, tt.reason, sink.records[0].Reason)
+		})
+	}
+}
+
+func TestMiddleware_Redactor(t *testing.T) {
+	sink := &recordingSink{}
+	handler := Middleware(sink, WithRedactor(func(command, option string, value any) any {
+		if command == "login" && option == "password" {
+			return "***"
+		}
+
+		return value
+	}))(func(context.Context, *discordgo.Session, *discordgo.InteractionCreate) error {
+		return nil
+	})
+
+	err := handler(context.Background(), nil, command(discordgo.ApplicationCommandInteractionData{
+		Name: "login",
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "username", Type: discordgo.ApplicationCommandOptionString, Value: "name"},
+			{Name: "password", Type: discordgo.ApplicationCommandOptionString, Value: "hunter2"},
+		},
+	}))
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]any{"username": "name", "password": "***"}, sink.records[0].Options)
+}
+
+func TestMiddleware_IgnoresOtherInteractions(t *testing.T) {
+	sink := &recordingSink{}
+	called := false
+	handler := Middleware(sink)(func(context.Context, *discordgo.Session, *discordgo.InteractionCreate) error {
+		called = true
+		return nil
+	})
+
+	err := handler(context.Background(), nil, &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type: discordgo.InteractionMessageComponent,
+		Data: discordgo.MessageComponentInteractionData{CustomID: "foo"},
+	}})
+	require.NoError(t, err)
+
+	require.True(t, called)
+	require.Empty(t, sink.records)
+}
+
+func TestMiddleware_Variant(t *testing.T) {
+	sink := &recordingSink{}
+
+	r := router.New()
+	r.Use(Middleware(sink))
+	r.RegisterCommand("foo", discordgo.ChatApplicationCommand, func(context.Context, *discordgo.Session, *discordgo.InteractionCreate, discordgo.ApplicationCommandInteractionData) error {
+		return nil
+	}, router.WithVariants(router.BucketByGuild, router.Variant{
+		Name:   "v2",
+		Weight: 100,
+		Handler: func(context.Context, *discordgo.Session, *discordgo.InteractionCreate, discordgo.ApplicationCommandInteractionData) error {
+			return nil
+		},
+	}))
+
+	r.Handle(nil, command(discordgo.ApplicationCommandInteractionData{Name: "foo", CommandType: discordgo.ChatApplicationCommand}))
+
+	require.Len(t, sink.records, 1)
+	require.Equal(t, "v2", sink.records[0].Variant)
+}
@@ -0,0 +1,213 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// SinkFunc is a function which is a Sink.
+type SinkFunc func(ctx context.Context, r Record) error
+
+func (f SinkFunc) Write(ctx context.Context, r Record) error {
+	return f(ctx, r)
+}
+
+// SlogSink logs records with the logger, at info level for successful invocations and warn level otherwise.
+type SlogSink struct {
+	log *slog.Logger
+}
+
+// NewSlogSink returns a sink which logs records with the logger.
+func NewSlogSink(l *slog.Logger) *SlogSink {
+	return &SlogSink{log: l}
+}
+
+func (s *SlogSink) Write(ctx context.Context, r Record) error {
+	attrs := []slog.Attr{
+		slog.String("interaction", r.InteractionID),
+		slog.String("user", r.UserID),
+		slog.String("guild", r.GuildID),
+		slog.String("channel", r.ChannelID),
+		slog.String("command", r.Command),
+		slog.Any("options", r.Options),
+		slog.String("outcome", string(r.Outcome)),
+		slog.Duration("duration", r.Duration),
+	}
+
+	if r.Variant != "" {
+		attrs = append(attrs, slog.String("variant", r.Variant))
+	}
+
+	if r.TargetID != "" {
+		attrs = append(attrs, slog.String("target", r.TargetID))
+	}
+
+	level := slog.LevelInfo
+	if r.Outcome != OutcomeSuccess {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", r.Error))
+	}
+
+	s.log.LogAttrs(ctx, level, "Command invoked", attrs...)
+
+	return nil
+}
+
+const (
+	defaultMaxSize    = 100 << 20
+	defaultMaxBackups = 5
+)
+
+// FileSink writes records to a file as JSON lines, rotating the file when it would exceed its maximum size. Rotated
+// files are renamed with a numeric suffix, e.g. "audit.jsonl.1" for the most recent, and the oldest are removed once
+// there are more than the maximum number of backups.
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+type FileSinkOption func(*FileSink)
+
+// WithMaxSize sets the size in bytes the file is rotated at. Defaults to 100MiB.
+func WithMaxSize(n int64) FileSinkOption {
+	return func(s *FileSink) {
+		s.maxSize = n
+	}
+}
+
+// WithMaxBackups sets how many rotated files are kept. Defaults to 5. If 0, rotated files are removed.
+func WithMaxBackups(n int) FileSinkOption {
+	return func(s *FileSink) {
+		s.maxBackups = n
+	}
+}
+
+// NewFileSink opens the file at the path for appending, creating it if necessary.
+func NewFileSink(path string, opts ...FileSinkOption) (*FileSink, error) {
+	s := &FileSink{
+		path:       path,
+		maxSize:    defaultMaxSize,
+		maxBackups: defaultMaxBackups,
+	}
+
+	for _, o := range opts {
+		o(s)
+	}
+
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *FileSink) Write(_ context.Context, r Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	b = append(b, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return os.ErrClosed
+	}
+
+	// a record larger than the maximum size is written to an empty file rather than dropped
+	if s.size > 0 && s.size+int64(len(b)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return fmt.Errorf("rotate audit file: %w", err)
+		}
+	}
+
+	n, err := s.file.Write(b)
+	s.size += int64(n)
+
+	return err
+}
+
+// Close closes the file. Records written after the sink is closed return os.ErrClosed.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+
+	return err
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	s.file = f
+	s.size = info.Size()
+
+	return nil
+}
+
+// rotate shifts the backups up by one, removing the oldest, renames the file to the first backup and opens a new file.
+// If the backups can not be shifted the file is reopened, so that the sink keeps writing to it.
+func (s *FileSink) rotate() error {
+	err := s.file.Close()
+	s.file = nil
+
+	if err == nil {
+		err = s.shift()
+	}
+
+	if err != nil {
+		return errors.Join(err, s.open())
+	}
+
+	return s.open()
+}
+
+// shift shifts the backups up by one, removing the oldest, and renames the file to the first backup
+func (s *FileSink) shift() error {
+	if err := os.Remove(s.backup(s.maxBackups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for n := s.maxBackups - 1; n > 0; n-- {
+		if err := os.Rename(s.backup(n), s.backup(n+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if s.maxBackups > 0 {
+		return os.Rename(s.path, s.backup(1))
+	}
+
+	return os.Remove(s.path)
+}
+
+func (s *FileSink) backup(n int) string {
+	return fmt.Sprintf("%s.%d", s.path, n)
+}
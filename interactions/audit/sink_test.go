@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, sink.Write(ctx, Record{InteractionID: "1", Command: "foo", Outcome: OutcomeSuccess}))
+	require.NoError(t, sink.Write(ctx, Record{InteractionID: "2", Command: "bar", Outcome: OutcomeError, Error: "oops"}))
+	require.NoError(t, sink.Close())
+
+	require.Equal(t, []string{"1", "2"}, interactionIDs(t, path))
+
+	require.ErrorIs(t, sink.Write(ctx, Record{}), os.ErrClosed)
+}
+
+func TestFileSink_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	sink, err := NewFileSink(path, WithMaxSize(1), WithMaxBackups(2))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, id := range []string{"1", "2", "3", "4"} {
+		require.NoError(t, sink.Write(ctx, Record{InteractionID: id}))
+	}
+	require.NoError(t, sink.Close())
+
+	require.Equal(t, []string{"4"}, interactionIDs(t, path))
+	require.Equal(t, []string{"3"}, interactionIDs(t, path+".1"))
+	require.Equal(t, []string{"2"}, interactionIDs(t, path+".2"))
+	require.NoFileExists(t, path+".3")
+}
+
+func TestFileSink_RotateFailed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	// a non-empty directory in place of the backup can be neither removed nor replaced
+	require.NoError(t, os.MkdirAll(filepath.Join(path+".1", "dir"), 0o700))
+
+	sink, err := NewFileSink(path, WithMaxSize(1), WithMaxBackups(1))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, sink.Write(ctx, Record{InteractionID: "1"}))
+	require.Error(t, sink.Write(ctx, Record{InteractionID: "2"}))
+
+	require.NoError(t, os.RemoveAll(path+".1"))
+	require.NoError(t, sink.Write(ctx, Record{InteractionID: "3"}))
+	require.NoError(t, sink.Close())
+
+	require.Equal(t, []string{"3"}, interactionIDs(t, path))
+	require.Equal(t, []string{"1"}, interactionIDs(t, path+".1"))
+}
+
+func TestFileSink_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	ctx := context.Background()
+
+	for _, id := range []string{"1", "2"} {
+		sink, err := NewFileSink(path)
+		require.NoError(t, err)
+		require.NoError(t, sink.Write(ctx, Record{InteractionID: id}))
+		require.NoError(t, sink.Close())
+	}
+
+	require.Equal(t, []string{"1", "2"}, interactionIDs(t, path))
+}
+
+func TestSlogSink(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sink := NewSlogSink(slog.New(slog.NewJSONHandler(buf, nil)))
+
+	err := sink.Write(context.Background(), Record{
+		InteractionID: "interaction",
+		Command:       "foo",
+		Outcome:       OutcomeError,
+		Error:         "oops",
+	})
+	require.NoError(t, err)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, "WARN", entry["level"])
+	require.Equal(t, "Command invoked", entry["msg"])
+	require.Equal(t, "foo", entry["command"])
+	require.Equal(t, "oops", entry["error"])
+}
+
+// interactionIDs returns the interaction IDs of the records in the file
+func interactionIDs(t *testing.T, path string) []string {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		ids = append(ids, r.InteractionID)
+	}
+
+	return ids
+}
@@ -0,0 +1,210 @@
+// Package flow implements multi-step conversations, such as a command followed by select menus and a modal, as state
+// machines whose state is persisted between interactions.
+package flow
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/interactions/router"
+	pkglog "github.com/elliotwms/bot/log"
+)
+
+const (
+	prefix = "flow"
+	// defaultTTL matches the lifetime of an interaction token, after which the flow's messages can not be updated
+	defaultTTL = 15 * time.Minute
+	// base32Alphabet is the alphabet of the instance IDs returned by rand.Text
+	base32Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+)
+
+// End is returned by a Step to end the flow, deleting its state.
+const End = ""
+
+// ErrUnknownStep is returned when a flow transitions to a step which has not been registered.
+var ErrUnknownStep = errors.New("unknown step")
+
+// Step handles an interaction in a flow instance, responding with the next prompt (e.g. a select menu or a modal) and
+// returning the name of the step which handles the user's answer, or End. Custom IDs for the prompt's components and
+// modals are created with State.CustomID. Changes to the state are persisted once the step returns without error.
+type Step func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, state *State) (next string, err error)
+
+// Flow routes the interactions of its instances to their current step. Steps must be registered before the flow is
+// started.
+type Flow struct {
+	name  string
+	store Store
+	ttl   time.Duration
+	log   *slog.Logger
+	start string
+	steps map[string]Step
+}
+
+type Option func(*Flow)
+
+// WithTTL sets how long an instance can wait for the user between steps before it is abandoned.
+func WithTTL(d time.Duration) Option {
+	return func(f *Flow) {
+		f.ttl = d
+	}
+}
+
+func WithLogger(l *slog.Logger) Option {
+	return func(f *Flow) {
+		f.log = l
+	}
+}
+
+// New creates a Flow, registering component and modal routes with the router for the custom IDs of its instances.
+// The name must be unique among the router's flows.
+func New(r *router.Router, name string, store Store, opts ...Option) *Flow {
+	f := &Flow{
+		name:  name,
+		store: store,
+		ttl:   defaultTTL,
+		log:   slog.New(pkglog.DiscardHandler),
+		steps: make(map[string]Step),
+	}
+
+	for _, o := range opts {
+		o(f)
+	}
+
+	r.RegisterComponent(f.customID(), f.handleComponent)
+	r.RegisterModal(f.customID(), f.handleModal)
+
+	return f
+}
+
+// Step registers a step. The first step registered is the step the flow starts at.
+func (f *Flow) Step(name string, step Step) *Flow {
+	if f.start == "" {
+		f.start = name
+	}
+
+	f.steps[name] = step
+
+	return f
+}
+
+// Start starts a new instance of the flow for the user who triggered the interaction, typically an application
+// command, by calling the first step.
+func (f *Flow) Start(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	state := &State{
+		ID:     rand.Text(),
+		Flow:   f.name,
+		Step:   f.start,
+		UserID: router.UserID(i.Interaction),
+		Data:   make(map[string]string),
+	}
+
+	return f.run(ctx, s, i, state)
+}
+
+func (f *Flow) handleComponent(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.MessageComponentInteractionData) error {
+	return f.resume(ctx, s, i, data.CustomID)
+}
+
+func (f *Flow) handleModal(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ModalSubmitInteractionData) error {
+	return f.resume(ctx, s, i, data.CustomID)
+}
+
+// resume loads the instance the custom ID belongs to and calls its current step
+func (f *Flow) resume(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) error {
+	id, _, _ := strings.Cut(strings.TrimPrefix(customID, f.customID()+":"), ":")
+	if !validID(id) {
+		return fmt.Errorf("invalid flow custom ID %q", customID)
+	}
+
+	state, err := f.store.Load(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return router.ResponderFromContext(ctx).Respond(ctx, &discordgo.InteractionResponseData{
+			Content: "This has expired, please start again",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	if state.UserID != router.UserID(i.Interaction) {
+		return router.ResponderFromContext(ctx).Respond(ctx, &discordgo.InteractionResponseData{
+			Content: "This can only be used by the user who started it",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+	}
+
+	return f.run(ctx, s, i, state)
+}
+
+// run calls the instance's current step, then persists its state or deletes it if the flow has ended
+func (f *Flow) run(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, state *State) error {
+	step, ok := f.steps[state.Step]
+	if !ok {
+		return fmt.Errorf("%w %q in flow %q", ErrUnknownStep, state.Step, f.name)
+	}
+
+	log := f.log.With(
+		slog.String("flow", f.name),
+		slog.String("instance", state.ID),
+		slog.String("step", state.Step),
+	)
+
+	next, err := step(ctx, s, i, state)
+	if err != nil {
+		return err
+	}
+
+	if next == End {
+		log.Debug("Flow ended")
+		return f.store.Delete(ctx, state.ID)
+	}
+
+	if _, ok := f.steps[next]; !ok {
+		return fmt.Errorf("%w %q in flow %q", ErrUnknownStep, next, f.name)
+	}
+
+	log.Debug("Flow advanced", slog.String("next", next))
+
+	state.Step = next
+	state.Expires = time.Now().Add(f.ttl)
+
+	return f.store.Save(ctx, state)
+}
+
+func (f *Flow) customID(values ...string) string {
+	return strings.Join(append([]string{prefix, f.name}, values...), ":")
+}
+
+// State is the state of a flow instance.
+type State struct {
+	// ID identifies the instance, and is included in the custom IDs of its components and modals
+	ID   string `json:"id"`
+	Flow string `json:"flow"`
+	// Step is the step which handles the instance's next interaction
+	Step string `json:"step"`
+	// UserID is the ID of the user who started the instance, who is the only user who can continue it
+	UserID string `json:"user_id"`
+	// Data holds the values collected by the flow's steps
+	Data map[string]string `json:"data"`
+	// Expires is when the instance is abandoned if the user has not continued it
+	Expires time.Time `json:"expires"`
+}
+
+// CustomID returns a custom ID which routes a component or modal to the instance, extended with ":" separated values
+// which the step can use to tell its components apart.
+func (s *State) CustomID(values ...string) string {
+	return strings.Join(append([]string{prefix, s.Flow, s.ID}, values...), ":")
+}
+
+// validID reports whether the instance ID could have been created by rand.Text. IDs come from custom IDs, which can be
+// crafted by users, so must be checked before they are used (e.g. as file names).
+func validID(id string) bool {
+	return len(id) == 26 && strings.Trim(id, base32Alphabet) == ""
+}
@@ -0,0 +1,205 @@
+package flow
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/interactions/router"
+	"github.com/elliotwms/bot/interactions/router/routertest"
+	"github.com/stretchr/testify/require"
+)
+
+type FlowStage struct {
+	t       testing.TB
+	require *require.Assertions
+
+	session  *discordgo.Session
+	recorder *routertest.Recorder
+	router   *router.Router
+	store    Store
+	flow     *Flow
+	// prompt is the custom ID of the last prompt sent by the flow
+	prompt string
+	// token is the token of the last interaction
+	token    string
+	finished map[string]string
+	err      error
+}
+
+func NewFlowStage(t *testing.T) (*FlowStage, *FlowStage, *FlowStage) {
+	s := &FlowStage{
+		t:       t,
+		require: require.New(t),
+		router:  router.New(router.WithLogger(slog.Default())),
+		store:   NewMemoryStore(),
+	}
+
+	s.session, s.recorder = routertest.NewSession()
+
+	return s, s, s
+}
+
+func (s *FlowStage) and() *FlowStage {
+	return s
+}
+
+func (s *FlowStage) a_file_store() *FlowStage {
+	store, err := NewFileStore(s.t.TempDir())
+	s.require.NoError(err)
+
+	s.store = store
+
+	return s
+}
+
+// a_flow_which_asks_for_a_colour_then_a_name starts with a select menu, then shows a modal, then finishes
+func (s *FlowStage) a_flow_which_asks_for_a_colour_then_a_name(ttl time.Duration) *FlowStage {
+	s.flow = New(s.router, "profile", s.store, WithTTL(ttl), WithLogger(slog.Default())).
+		Step("start", func(ctx context.Context, ds *discordgo.Session, i *discordgo.InteractionCreate, state *State) (string, error) {
+			s.prompt = state.CustomID("colour")
+
+			return "colour", ds.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "Pick a colour",
+					Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+						discordgo.SelectMenu{CustomID: s.prompt, Options: []discordgo.SelectMenuOption{{Label: "Red", Value: "red"}}},
+					}}},
+				},
+			}, discordgo.WithContext(ctx))
+		}).
+		Step("colour", func(ctx context.Context, ds *discordgo.Session, i *discordgo.InteractionCreate, state *State) (string, error) {
+			state.Data["colour"] = i.MessageComponentData().Values[0]
+			s.prompt = state.CustomID("name")
+
+			return "name", ds.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseModal,
+				Data: &discordgo.InteractionResponseData{
+					CustomID: s.prompt,
+					Title:    "Name",
+					Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+						discordgo.TextInput{CustomID: "name", Label: "Name", Style: discordgo.TextInputShort},
+					}}},
+				},
+			}, discordgo.WithContext(ctx))
+		}).
+		Step("name", func(ctx context.Context, ds *discordgo.Session, i *discordgo.InteractionCreate, state *State) (string, error) {
+			row := i.ModalSubmitData().Components[0].(*discordgo.ActionsRow)
+			state.Data["name"] = row.Components[0].(*discordgo.TextInput).Value
+			s.finished = state.Data
+
+			return End, ds.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{Content: "Done"},
+			}, discordgo.WithContext(ctx))
+		})
+
+	return s
+}
+
+func (s *FlowStage) the_flow_is_started_by(userID string) *FlowStage {
+	s.token = "start"
+	s.err = s.flow.Start(context.Background(), s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:    "start",
+			Token: s.token,
+			Type:  discordgo.InteractionApplicationCommand,
+			User:  &discordgo.User{ID: userID},
+			Data:  discordgo.ApplicationCommandInteractionData{Name: "profile"},
+		},
+	})
+
+	return s
+}
+
+func (s *FlowStage) the_colour_is_selected_by(colour, userID string) *FlowStage {
+	s.token = "colour"
+	s.router.Handle(s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			Token: s.token,
+			Type:  discordgo.InteractionMessageComponent,
+			User:  &discordgo.User{ID: userID},
+			Data: discordgo.MessageComponentInteractionData{
+				CustomID:      s.prompt,
+				ComponentType: discordgo.SelectMenuComponent,
+				Values:        []string{colour},
+			},
+		},
+	})
+
+	return s
+}
+
+func (s *FlowStage) the_name_is_submitted_by(name, userID string) *FlowStage {
+	s.token = "name"
+	s.router.Handle(s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			Token: s.token,
+			Type:  discordgo.InteractionModalSubmit,
+			User:  &discordgo.User{ID: userID},
+			Data: discordgo.ModalSubmitInteractionData{
+				CustomID: s.prompt,
+				Components: []discordgo.MessageComponent{&discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					&discordgo.TextInput{CustomID: "name", Value: name},
+				}}},
+			},
+		},
+	})
+
+	return s
+}
+
+func (s *FlowStage) time_passes(d time.Duration) *FlowStage {
+	time.Sleep(d)
+
+	return s
+}
+
+func (s *FlowStage) no_error_should_be_returned() *FlowStage {
+	s.require.NoError(s.err)
+
+	return s
+}
+
+func (s *FlowStage) the_last_response_should_be(t discordgo.InteractionResponseType, content string) *FlowStage {
+	responses := s.recorder.Responses(s.token)
+	s.require.NotEmpty(responses)
+
+	last := responses[len(responses)-1]
+	s.require.Equal(t, last.Type, "unexpected response type")
+	s.require.Equal(content, last.Message.Content)
+
+	return s
+}
+
+func (s *FlowStage) the_flow_should_be_waiting_for(step string) *FlowStage {
+	state, err := s.store.Load(context.Background(), s.instanceID())
+	s.require.NoError(err)
+	s.require.Equal(step, state.Step)
+
+	return s
+}
+
+func (s *FlowStage) the_flow_should_have_finished_with(data map[string]string) *FlowStage {
+	s.require.Equal(data, s.finished)
+
+	_, err := s.store.Load(context.Background(), s.instanceID())
+	s.require.ErrorIs(err, ErrNotFound)
+
+	return s
+}
+
+func (s *FlowStage) the_flow_should_not_have_finished() *FlowStage {
+	s.require.Nil(s.finished)
+
+	return s
+}
+
+// instanceID returns the ID of the instance which sent the last prompt
+func (s *FlowStage) instanceID() string {
+	return strings.Split(s.prompt, ":")[2]
+}
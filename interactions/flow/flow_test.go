@@ -0,0 +1,84 @@
+package flow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestFlow(t *testing.T) {
+	given, when, then := NewFlowStage(t)
+
+	given.
+		a_flow_which_asks_for_a_colour_then_a_name(time.Minute)
+
+	when.
+		the_flow_is_started_by("user")
+
+	then.
+		no_error_should_be_returned().and().
+		the_last_response_should_be(discordgo.InteractionResponseChannelMessageWithSource, "Pick a colour").and().
+		the_flow_should_be_waiting_for("colour")
+
+	when.
+		the_colour_is_selected_by("red", "user")
+
+	then.
+		the_last_response_should_be(discordgo.InteractionResponseModal, "").and().
+		the_flow_should_be_waiting_for("name")
+
+	when.
+		the_name_is_submitted_by("Elliot", "user")
+
+	then.
+		the_last_response_should_be(discordgo.InteractionResponseChannelMessageWithSource, "Done").and().
+		the_flow_should_have_finished_with(map[string]string{"colour": "red", "name": "Elliot"})
+}
+
+func TestFlow_FileStore(t *testing.T) {
+	given, when, then := NewFlowStage(t)
+
+	given.
+		a_file_store().and().
+		a_flow_which_asks_for_a_colour_then_a_name(time.Minute).and().
+		the_flow_is_started_by("user")
+
+	when.
+		the_colour_is_selected_by("red", "user").and().
+		the_name_is_submitted_by("Elliot", "user")
+
+	then.
+		the_flow_should_have_finished_with(map[string]string{"colour": "red", "name": "Elliot"})
+}
+
+func TestFlow_OtherUser(t *testing.T) {
+	given, when, then := NewFlowStage(t)
+
+	given.
+		a_flow_which_asks_for_a_colour_then_a_name(time.Minute).and().
+		the_flow_is_started_by("user")
+
+	when.
+		the_colour_is_selected_by("red", "other")
+
+	then.
+		the_last_response_should_be(discordgo.InteractionResponseChannelMessageWithSource, "This can only be used by the user who started it").and().
+		the_flow_should_be_waiting_for("colour")
+}
+
+func TestFlow_Expired(t *testing.T) {
+	given, when, then := NewFlowStage(t)
+
+	given.
+		a_flow_which_asks_for_a_colour_then_a_name(10 * time.Millisecond).and().
+		the_flow_is_started_by("user").and().
+		time_passes(20 * time.Millisecond)
+
+	when.
+		the_colour_is_selected_by("red", "user")
+
+	then.
+		the_last_response_should_be(discordgo.InteractionResponseChannelMessageWithSource, "This has expired, please start again").and().
+		the_flow_should_not_have_finished()
+}
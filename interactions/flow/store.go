@@ -0,0 +1,205 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by a Store when an instance does not exist or has expired.
+var ErrNotFound = errors.New("flow instance not found")
+
+// Store persists the state of flow instances between interactions. Implementations backed by shared storage allow
+// instances to be continued by any replica.
+type Store interface {
+	// Load returns the state of the instance, or ErrNotFound if it does not exist or has expired
+	Load(ctx context.Context, id string) (*State, error)
+	// Save creates or replaces the state of the instance
+	Save(ctx context.Context, state *State) error
+	// Delete removes the state of the instance, if it exists
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore is an in-memory Store. Expired instances are removed as other instances are saved.
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]State
+	now    func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		states: make(map[string]State),
+		now:    time.Now,
+	}
+}
+
+func (m *MemoryStore) Load(_ context.Context, id string) (*State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.states[id]
+	if !ok || !state.Expires.After(m.now()) {
+		return nil, ErrNotFound
+	}
+
+	return copyState(state), nil
+}
+
+func (m *MemoryStore) Save(_ context.Context, state *State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	for id, s := range m.states {
+		if !s.Expires.After(now) {
+			delete(m.states, id)
+		}
+	}
+
+	m.states[state.ID] = *copyState(*state)
+
+	return nil
+}
+
+func (m *MemoryStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.states, id)
+
+	return nil
+}
+
+// copyState copies the state, so that the stored state can not be modified by steps
+func copyState(s State) *State {
+	data := make(map[string]string, len(s.Data))
+	for k, v := range s.Data {
+		data[k] = v
+	}
+	s.Data = data
+
+	return &s
+}
+
+// FileStore is a Store which persists each instance as a JSON file in a directory, allowing instances to survive
+// restarts. Expired instances are removed when loaded, or by Prune.
+type FileStore struct {
+	dir string
+	now func() time.Time
+}
+
+// NewFileStore creates a FileStore in the directory, creating it if necessary.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{dir: dir, now: time.Now}, nil
+}
+
+func (f *FileStore) Load(ctx context.Context, id string) (*State, error) {
+	path, err := f.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state State
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("invalid state for flow instance %q: %w", id, err)
+	}
+
+	if !state.Expires.After(f.now()) {
+		return nil, errors.Join(ErrNotFound, f.Delete(ctx, id))
+	}
+
+	return &state, nil
+}
+
+// Save writes the state to a temporary file before renaming it, so that a partially written state is never loaded.
+func (f *FileStore) Save(_ context.Context, state *State) error {
+	path, err := f.path(state.ID)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(f.dir, state.ID+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (f *FileStore) Delete(_ context.Context, id string) error {
+	path, err := f.path(id)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Prune removes the files of expired instances, which would otherwise remain until they are loaded.
+func (f *FileStore) Prune(ctx context.Context) error {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			continue
+		}
+
+		if _, err := f.Load(ctx, id); err != nil && !errors.Is(err, ErrNotFound) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (f *FileStore) path(id string) (string, error) {
+	if !validID(id) {
+		return "", fmt.Errorf("invalid flow instance ID %q", id)
+	}
+
+	return filepath.Join(f.dir, id+".json"), nil
+}
@@ -0,0 +1,87 @@
+package flow
+
+import (
+	"context"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStores(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	stores := map[string]Store{
+		"memory": NewMemoryStore(),
+		"file":   fileStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			state := &State{
+				ID:      rand.Text(),
+				Flow:    "flow",
+				Step:    "step",
+				UserID:  "user",
+				Data:    map[string]string{"foo": "bar"},
+				Expires: time.Now().Add(time.Minute),
+			}
+
+			_, err := store.Load(ctx, state.ID)
+			require.ErrorIs(t, err, ErrNotFound)
+
+			require.NoError(t, store.Save(ctx, state))
+
+			loaded, err := store.Load(ctx, state.ID)
+			require.NoError(t, err)
+			require.Equal(t, state.Data, loaded.Data)
+			require.Equal(t, state.Step, loaded.Step)
+			require.WithinDuration(t, state.Expires, loaded.Expires, 0)
+
+			require.NoError(t, store.Delete(ctx, state.ID))
+			require.NoError(t, store.Delete(ctx, state.ID))
+
+			_, err = store.Load(ctx, state.ID)
+			require.ErrorIs(t, err, ErrNotFound)
+
+			state.Expires = time.Now().Add(-time.Second)
+			require.NoError(t, store.Save(ctx, state))
+
+			_, err = store.Load(ctx, state.ID)
+			require.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+func TestFileStore_Prune(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	expired := &State{ID: rand.Text(), Expires: time.Now().Add(-time.Second)}
+	active := &State{ID: rand.Text(), Expires: time.Now().Add(time.Minute)}
+
+	require.NoError(t, store.Save(ctx, expired))
+	require.NoError(t, store.Save(ctx, active))
+	require.NoError(t, store.Prune(ctx))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, active.ID+".json", entries[0].Name())
+}
+
+func TestFileStore_InvalidID(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(filepath.Join(dir, "flows"))
+	require.NoError(t, err)
+
+	_, err = store.Load(context.Background(), "../../etc/passwd")
+	require.ErrorContains(t, err, "invalid flow instance ID")
+}
@@ -2,6 +2,9 @@ package migrator
 
 import (
 	"context"
+	"fmt"
+	"maps"
+	"slices"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -17,6 +20,30 @@ type Migrator struct {
 	appID    string
 	commands []*discordgo.ApplicationCommand
 	guildID  string
+	// scoped are commands which are only registered in some guilds
+	scoped []scopedCommand
+	// store persists the guilds scoped commands were migrated to, or nil to only remember them in migrated
+	store    GuildStore
+	migrated []string
+}
+
+// GuildStore persists the IDs of the guilds scoped commands (see Migrator.WithGuildApplicationCommand) were last
+// migrated to, so that their commands can be removed once they are no longer migrated to them, e.g. when a feature is
+// disabled in a guild between runs.
+type GuildStore interface {
+	// Guilds returns the IDs of the guilds commands were last migrated to
+	Guilds(ctx context.Context) ([]string, error)
+	// SetGuilds replaces the IDs of the guilds commands were last migrated to
+	SetGuilds(ctx context.Context, guildIDs []string) error
+}
+
+// GuildsFunc returns the IDs of the guilds a command should be registered in, or global as true if it should be
+// registered globally instead.
+type GuildsFunc func(ctx context.Context) (guildIDs []string, global bool, err error)
+
+type scopedCommand struct {
+	command *discordgo.ApplicationCommand
+	guilds  GuildsFunc
 }
 
 type Option func(*Migrator)
@@ -41,6 +68,14 @@ func WithGuildID(id string) Option {
 	}
 }
 
+// WithGuildStore persists the guilds scoped commands are migrated to in the store. By default they are only remembered
+// by the migrator, so commands are only removed from guilds they were migrated to by an earlier call to Migrate.
+func WithGuildStore(store GuildStore) Option {
+	return func(migrator *Migrator) {
+		migrator.store = store
+	}
+}
+
 // WithApplicationCommand registers an application command to be migrated when Migrate is called.
 func (m *Migrator) WithApplicationCommand(c *discordgo.ApplicationCommand) *Migrator {
 	m.commands = append(m.commands, c)
@@ -48,9 +83,119 @@ func (m *Migrator) WithApplicationCommand(c *discordgo.ApplicationCommand) *Migr
 	return m
 }
 
-// Migrate migrates the application's commands.
+// WithGuildApplicationCommand registers an application command to be migrated to the guilds returned by guilds when
+// Migrate is called, e.g. the guilds a feature is enabled in. If guilds returns global as true, the command is migrated
+// with the other commands instead. When the migrator has a guild ID (see WithGuildID), the command is only migrated if
+// that guild is returned.
+//
+// Guilds which are no longer returned have their scoped commands removed, as long as the migrator knows the command was
+// migrated there (see WithGuildStore). Gate the command's handler as well (e.g. with features.Require) so that it can
+// not be invoked in guilds which still have it.
+func (m *Migrator) WithGuildApplicationCommand(c *discordgo.ApplicationCommand, guilds GuildsFunc) *Migrator {
+	m.scoped = append(m.scoped, scopedCommand{command: c, guilds: guilds})
+
+	return m
+}
+
+// Migrate migrates the application's commands. Install types and contexts are only supported by global commands, so
+// they are omitted when migrating to a guild.
 func (m *Migrator) Migrate(ctx context.Context) error {
-	_, err := m.s.ApplicationCommandBulkOverwrite(m.appID, m.guildID, m.commands, discordgo.WithContext(ctx))
+	commands, guilds, err := m.resolve(ctx)
+	if err != nil {
+		return err
+	}
+
+	if m.guildID != "" {
+		commands = guildCommands(commands)
+	}
+
+	if _, err := m.s.ApplicationCommandBulkOverwrite(m.appID, m.guildID, commands, discordgo.WithContext(ctx)); err != nil {
+		return err
+	}
+
+	previous, err := m.previousGuilds(ctx)
+	if err != nil {
+		return fmt.Errorf("load migrated guilds: %w", err)
+	}
+
+	migrated := slices.Sorted(maps.Keys(guilds))
+
+	// remove the scoped commands from the guilds they are no longer migrated to
+	for _, guildID := range previous {
+		if _, ok := guilds[guildID]; !ok && guildID != m.guildID {
+			guilds[guildID] = nil
+		}
+	}
+
+	for _, guildID := range slices.Sorted(maps.Keys(guilds)) {
+		_, err := m.s.ApplicationCommandBulkOverwrite(m.appID, guildID, guildCommands(guilds[guildID]), discordgo.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("migrate commands to guild %s: %w", guildID, err)
+		}
+	}
+
+	if err := m.setGuilds(ctx, migrated); err != nil {
+		return fmt.Errorf("save migrated guilds: %w", err)
+	}
+
+	return nil
+}
+
+// previousGuilds returns the guilds scoped commands were last migrated to
+func (m *Migrator) previousGuilds(ctx context.Context) ([]string, error) {
+	if m.store == nil {
+		return m.migrated, nil
+	}
+
+	return m.store.Guilds(ctx)
+}
+
+// setGuilds records the guilds scoped commands were migrated to
+func (m *Migrator) setGuilds(ctx context.Context, guildIDs []string) error {
+	m.migrated = guildIDs
+
+	if m.store == nil {
+		return nil
+	}
+
+	return m.store.SetGuilds(ctx, guildIDs)
+}
+
+// resolve returns the commands to migrate with the migrator's guild ID, and the scoped commands to migrate to each
+// other guild
+func (m *Migrator) resolve(ctx context.Context) ([]*discordgo.ApplicationCommand, map[string][]*discordgo.ApplicationCommand, error) {
+	commands := slices.Clone(m.commands)
+	guilds := make(map[string][]*discordgo.ApplicationCommand)
+
+	for _, sc := range m.scoped {
+		ids, global, err := sc.guilds(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("resolve guilds of command %q: %w", sc.command.Name, err)
+		}
+
+		switch {
+		case global || (m.guildID != "" && slices.Contains(ids, m.guildID)):
+			commands = append(commands, sc.command)
+		case m.guildID == "":
+			for _, id := range ids {
+				guilds[id] = append(guilds[id], sc.command)
+			}
+		}
+	}
+
+	return commands, guilds, nil
+}
+
+// guildCommands copies the commands without the fields guild commands do not support
+func guildCommands(commands []*discordgo.ApplicationCommand) []*discordgo.ApplicationCommand {
+	guild := make([]*discordgo.ApplicationCommand, 0, len(commands))
+
+	for _, c := range commands {
+		c := *c
+		c.IntegrationTypes = nil
+		c.Contexts = nil
+		guild = append(guild, &c)
+	}
 
-	return err
+	return guild
 }
@@ -2,6 +2,7 @@ package migrator
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/bwmarrin/discordgo"
@@ -52,11 +53,138 @@ func TestMigrator_Migrate(t *testing.T) {
 	}
 }
 
+func TestMigrator_Migrate_GuildOmitsContexts(t *testing.T) {
+	updater := &testCommandUpdater{}
+	m := &Migrator{s: updater, appID: "foo", guildID: "guildid"}
+
+	c := &discordgo.ApplicationCommand{
+		Name:             "foo",
+		IntegrationTypes: &[]discordgo.ApplicationIntegrationType{discordgo.ApplicationIntegrationUserInstall},
+		Contexts:         &[]discordgo.InteractionContextType{discordgo.InteractionContextPrivateChannel},
+	}
+	m.WithApplicationCommand(c)
+
+	require.NoError(t, m.Migrate(context.Background()))
+	require.Len(t, updater.commands, 1)
+	require.Equal(t, "foo", updater.commands[0].Name)
+	require.Nil(t, updater.commands[0].IntegrationTypes)
+	require.Nil(t, updater.commands[0].Contexts)
+
+	// the registered command is unchanged
+	require.NotNil(t, c.Contexts)
+}
+
+func TestMigrator_Migrate_GuildApplicationCommands(t *testing.T) {
+	guilds := func(ids []string, global bool) GuildsFunc {
+		return func(context.Context) ([]string, bool, error) {
+			return ids, global, nil
+		}
+	}
+
+	tests := map[string]struct {
+		guildID string
+		want    map[string][]string
+	}{
+		"no guild id": {
+			want: map[string][]string{
+				"":  {"foo", "global"},
+				"a": {"scoped", "other"},
+				"b": {"scoped"},
+				"c": {"other"},
+			},
+		},
+		"with guild id": {
+			guildID: "a",
+			want: map[string][]string{
+				"a": {"foo", "scoped", "other", "global"},
+			},
+		},
+		"with guild id not enabled": {
+			guildID: "d",
+			want: map[string][]string{
+				"d": {"foo", "global"},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			updater := &testCommandUpdater{}
+			m := &Migrator{s: updater, appID: "foo", guildID: tt.guildID}
+
+			m.WithApplicationCommand(&discordgo.ApplicationCommand{Name: "foo"})
+			m.WithGuildApplicationCommand(&discordgo.ApplicationCommand{Name: "scoped"}, guilds([]string{"a", "b"}, false))
+			m.WithGuildApplicationCommand(&discordgo.ApplicationCommand{Name: "other"}, guilds([]string{"a", "c"}, false))
+			m.WithGuildApplicationCommand(&discordgo.ApplicationCommand{Name: "global"}, guilds([]string{"a"}, true))
+
+			require.NoError(t, m.Migrate(context.Background()))
+			require.Equal(t, tt.want, updater.guilds)
+		})
+	}
+}
+
+func TestMigrator_Migrate_RemovesGuildCommands(t *testing.T) {
+	enabled := []string{"a", "b"}
+
+	updater := &testCommandUpdater{}
+	m := &Migrator{s: updater, appID: "foo"}
+
+	m.WithGuildApplicationCommand(&discordgo.ApplicationCommand{Name: "scoped"}, func(context.Context) ([]string, bool, error) {
+		return enabled, false, nil
+	})
+
+	require.NoError(t, m.Migrate(context.Background()))
+
+	enabled = []string{"a"}
+	require.NoError(t, m.Migrate(context.Background()))
+
+	require.Equal(t, map[string][]string{
+		"":  {},
+		"a": {"scoped"},
+		"b": {},
+	}, updater.guilds)
+
+	// guilds are only removed from once
+	updater.guilds = nil
+	require.NoError(t, m.Migrate(context.Background()))
+	require.NotContains(t, updater.guilds, "b")
+}
+
+func TestMigrator_Migrate_GuildStore(t *testing.T) {
+	store := &testGuildStore{guilds: []string{"b"}}
+	updater := &testCommandUpdater{}
+	m := New(nil, "foo", WithGuildStore(store))
+	m.s = updater
+
+	m.WithGuildApplicationCommand(&discordgo.ApplicationCommand{Name: "scoped"}, func(context.Context) ([]string, bool, error) {
+		return []string{"a"}, false, nil
+	})
+
+	require.NoError(t, m.Migrate(context.Background()))
+
+	require.Equal(t, []string{}, updater.guilds["b"])
+	require.Equal(t, []string{"a"}, store.guilds)
+}
+
+func TestMigrator_Migrate_GuildsError(t *testing.T) {
+	updater := &testCommandUpdater{}
+	m := &Migrator{s: updater, appID: "foo"}
+
+	m.WithGuildApplicationCommand(&discordgo.ApplicationCommand{Name: "scoped"}, func(context.Context) ([]string, bool, error) {
+		return nil, false, errors.New("oops")
+	})
+
+	require.EqualError(t, m.Migrate(context.Background()), `resolve guilds of command "scoped": oops`)
+	require.Zero(t, updater.calls)
+}
+
 type testCommandUpdater struct {
 	calls    int
 	appID    string
 	guildID  string
 	commands []*discordgo.ApplicationCommand
+	// guilds are the names of the commands migrated to each guild
+	guilds map[string][]string
 }
 
 func (t *testCommandUpdater) ApplicationCommandBulkOverwrite(appID, guildID string, commands []*discordgo.ApplicationCommand, opts ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error) {
@@ -65,5 +193,30 @@ func (t *testCommandUpdater) ApplicationCommandBulkOverwrite(appID, guildID stri
 	t.appID, t.guildID = appID, guildID
 	t.commands = commands
 
+	if t.guilds == nil {
+		t.guilds = make(map[string][]string)
+	}
+
+	// bulk overwrites replace the guild's commands
+	names := []string{}
+	for _, c := range commands {
+		names = append(names, c.Name)
+	}
+	t.guilds[guildID] = names
+
 	return commands, nil
 }
+
+type testGuildStore struct {
+	guilds []string
+}
+
+func (s *testGuildStore) Guilds(context.Context) ([]string, error) {
+	return s.guilds, nil
+}
+
+func (s *testGuildStore) SetGuilds(_ context.Context, guildIDs []string) error {
+	s.guilds = guildIDs
+
+	return nil
+}
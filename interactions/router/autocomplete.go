@@ -0,0 +1,60 @@
+package router
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// AutocompleteHandler returns the choices to suggest for the focused option of a chat command.
+type AutocompleteHandler func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) (choices []*discordgo.ApplicationCommandOptionChoice, err error)
+
+// RegisterAutocomplete registers a handler for autocomplete interactions for the named chat command. The router
+// responds to the interaction with the choices returned by the handler.
+func (r *Router) RegisterAutocomplete(name string, handler AutocompleteHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.autocompleteHandlers[name] = handlerRoute[AutocompleteHandler]{handler: handler}
+}
+
+// UnregisterAutocomplete removes the autocomplete handler for the named chat command, if registered.
+func (r *Router) UnregisterAutocomplete(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.autocompleteHandlers, name)
+}
+
+func (r *Router) handleAutocomplete(ctx context.Context, s *discordgo.Session, e *discordgo.InteractionCreate) {
+	command := e.ApplicationCommandData()
+
+	log := r.log.With(
+		slog.String("interaction", e.ID),
+		slog.String("command", command.Name),
+	)
+
+	r.mu.RLock()
+	route, ok := r.autocompleteHandlers[command.Name]
+	r.mu.RUnlock()
+	if !ok {
+		log.Error("Handler not found for autocomplete", "name", command.Name)
+		return
+	}
+
+	err := r.run(ctx, s, e, route.middleware, func(ctx context.Context, s *discordgo.Session, e *discordgo.InteractionCreate) error {
+		choices, err := route.handler(ctx, s, e, command)
+		if err != nil {
+			return err
+		}
+
+		return s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+			Data: &discordgo.InteractionResponseData{Choices: choices},
+		}, discordgo.WithContext(ctx))
+	})
+	if err != nil {
+		r.fail(ctx, log, e, command.Name, err)
+	}
+}
@@ -0,0 +1,114 @@
+package router
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// CollectFilter reports whether a component interaction should be collected.
+type CollectFilter func(i *discordgo.InteractionCreate) bool
+
+// FromUser collects component interactions triggered by the user.
+func FromUser(userID string) CollectFilter {
+	return func(i *discordgo.InteractionCreate) bool {
+		return UserID(i.Interaction) == userID
+	}
+}
+
+// collector receives the component interactions on a message which match its filter
+type collector struct {
+	messageID string
+	filter    CollectFilter
+	ch        chan *discordgo.InteractionCreate
+	done      chan struct{}
+	// senders tracks the interactions being sent to ch, which must complete before ch is closed
+	senders sync.WaitGroup
+}
+
+// Collect returns a channel of the component interactions on the message which match the filter (if any), allowing
+// interactive flows to be written as sequential code. Collected interactions are not passed to the router's component
+// routes, and must be responded to by the receiver (e.g. with InteractionResponseUpdateMessage). The channel is closed
+// when the context is done, the timeout passes or the router is closed.
+func (r *Router) Collect(ctx context.Context, messageID string, filter CollectFilter, timeout time.Duration) <-chan *discordgo.InteractionCreate {
+	c := &collector{
+		messageID: messageID,
+		filter:    filter,
+		ch:        make(chan *discordgo.InteractionCreate),
+		done:      make(chan struct{}),
+	}
+
+	r.mu.Lock()
+	r.collectors[messageID] = append(r.collectors[messageID], c)
+	r.mu.Unlock()
+
+	go func() {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		case <-r.closed:
+		}
+
+		r.removeCollector(c)
+	}()
+
+	return c.ch
+}
+
+// Close stops the router's collectors. The router continues to handle interactions with its routes.
+func (r *Router) Close() {
+	r.closeOnce.Do(func() {
+		close(r.closed)
+	})
+}
+
+func (r *Router) removeCollector(c *collector) {
+	r.mu.Lock()
+	r.collectors[c.messageID] = slices.DeleteFunc(r.collectors[c.messageID], func(e *collector) bool { return e == c })
+	if len(r.collectors[c.messageID]) == 0 {
+		delete(r.collectors, c.messageID)
+	}
+	r.mu.Unlock()
+
+	close(c.done)
+	c.senders.Wait()
+	close(c.ch)
+}
+
+// collect sends the interaction to the first collector for its message which matches it, reporting whether it was
+// collected
+func (r *Router) collect(e *discordgo.InteractionCreate) bool {
+	if e.Message == nil {
+		return false
+	}
+
+	r.mu.RLock()
+	var c *collector
+	for _, candidate := range r.collectors[e.Message.ID] {
+		if candidate.filter == nil || candidate.filter(e) {
+			c = candidate
+			c.senders.Add(1)
+			break
+		}
+	}
+	r.mu.RUnlock()
+
+	if c == nil {
+		return false
+	}
+
+	defer c.senders.Done()
+
+	select {
+	case c.ch <- e:
+		return true
+	case <-c.done:
+		return false
+	}
+}
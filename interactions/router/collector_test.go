@@ -0,0 +1,56 @@
+package router
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRouter_Collect(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_handler_is_registered_for_component("foo").and().
+		a_collector_for_message("message", FromUser("user"), time.Minute)
+
+	when.
+		the_message_component_is_clicked_by("message", "foo:1", "user")
+
+	then.
+		the_component_should_be_collected("foo:1")
+
+	when.
+		the_message_component_is_clicked_by("message", "foo:2", "user")
+
+	then.
+		the_component_should_be_collected("foo:2").and().
+		the_handler_should_have_been_called_n_times(0)
+}
+
+func TestRouter_Collect_Filtered(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_handler_is_registered_for_component("foo").and().
+		a_collector_for_message("message", FromUser("user"), 50*time.Millisecond)
+
+	when.
+		the_message_component_is_clicked_by_and_routed("message", "foo:1", "other").and().
+		the_message_component_is_clicked_by_and_routed("other", "foo:2", "user")
+
+	then.
+		the_collector_should_be_closed().and().
+		the_handler_should_have_been_called_n_times(2)
+}
+
+func TestRouter_Collect_Close(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_collector_for_message("message", nil, time.Minute)
+
+	when.
+		the_router_is_closed()
+
+	then.
+		the_collector_should_be_closed()
+}
@@ -0,0 +1,75 @@
+package router
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type ComponentHandler func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.MessageComponentInteractionData) (err error)
+
+// RegisterComponent registers a handler for message components with the custom ID. The handler is also called for
+// components whose custom IDs extend the custom ID with ":" separated values (e.g. a handler registered for "vote"
+// handles "vote:yes"), with the most specific registered custom ID taking precedence.
+func (r *Router) RegisterComponent(customID string, handler ComponentHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.componentHandlers[customID] = handlerRoute[ComponentHandler]{handler: handler}
+}
+
+// UnregisterComponent removes the handler for the message component custom ID, if registered.
+func (r *Router) UnregisterComponent(customID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.componentHandlers, customID)
+}
+
+func (r *Router) handleComponent(ctx context.Context, s *discordgo.Session, e *discordgo.InteractionCreate) {
+	component := e.MessageComponentData()
+
+	log := r.log.With(
+		slog.String("interaction", e.ID),
+		slog.String("custom_id", component.CustomID),
+	)
+
+	if r.collect(e) {
+		log.Debug("Collected message component")
+		return
+	}
+
+	r.mu.RLock()
+	route, ok := lookupCustomID(r.componentHandlers, component.CustomID)
+	r.mu.RUnlock()
+	if !ok {
+		log.Error("Handler not found for message component")
+		return
+	}
+
+	err := r.run(ctx, s, e, route.middleware, func(ctx context.Context, s *discordgo.Session, e *discordgo.InteractionCreate) error {
+		return route.handler(ctx, s, e, component)
+	})
+	if err != nil {
+		r.fail(ctx, log, e, component.CustomID, err)
+	}
+}
+
+// lookupCustomID finds the handler registered for the most specific prefix of the custom ID.
+func lookupCustomID[H any](handlers map[string]H, customID string) (H, bool) {
+	for {
+		if h, ok := handlers[customID]; ok {
+			return h, true
+		}
+
+		n := strings.LastIndex(customID, ":")
+		if n < 0 {
+			var zero H
+			return zero, false
+		}
+
+		customID = customID[:n]
+	}
+}
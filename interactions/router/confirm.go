@@ -0,0 +1,135 @@
+package router
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	confirmPrefix         = "confirm"
+	defaultConfirmTimeout = time.Minute
+)
+
+// ErrConfirmTimeout is returned by Confirm when the user does not respond to the prompt before the timeout.
+var ErrConfirmTimeout = errors.New("confirmation timed out")
+
+type confirmConfig struct {
+	timeout                   time.Duration
+	confirmLabel, cancelLabel string
+}
+
+type ConfirmOption func(*confirmConfig)
+
+// WithConfirmTimeout sets how long Confirm waits for the user to respond.
+func WithConfirmTimeout(d time.Duration) ConfirmOption {
+	return func(c *confirmConfig) {
+		c.timeout = d
+	}
+}
+
+// WithConfirmLabels sets the labels of the confirm and cancel buttons.
+func WithConfirmLabels(confirm, cancel string) ConfirmOption {
+	return func(c *confirmConfig) {
+		c.confirmLabel, c.cancelLabel = confirm, cancel
+	}
+}
+
+// Confirm responds to the interaction with an ephemeral prompt with confirm and cancel buttons, and blocks until the
+// user who triggered the interaction clicks one, the context is done or the timeout passes. The prompt is updated to
+// reflect the outcome. Confirm returns true if the user confirmed, false if they cancelled, or ErrConfirmTimeout or the
+// context's error otherwise.
+func Confirm(ctx context.Context, rs *Responder, prompt string, opts ...ConfirmOption) (bool, error) {
+	c := confirmConfig{
+		timeout:      defaultConfirmTimeout,
+		confirmLabel: "Confirm",
+		cancelLabel:  "Cancel",
+	}
+
+	for _, o := range opts {
+		o(&c)
+	}
+
+	customID := confirmPrefix + ":" + nonce()
+	user := UserID(rs.interaction.Interaction)
+	choices := make(chan bool, 1)
+
+	// register a temporary route for the prompt's buttons
+	rs.router.RegisterComponent(customID, func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.MessageComponentInteractionData) error {
+		if UserID(i.Interaction) != user {
+			return respondEphemeral(ctx, s, i, "This prompt can only be answered by the user who triggered it")
+		}
+
+		confirmed := data.CustomID == customID+":confirm"
+		outcome := "Cancelled"
+		if confirmed {
+			outcome = "Confirmed"
+		}
+
+		select {
+		case choices <- confirmed:
+		default:
+			// the prompt has already been answered
+		}
+
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: confirmOutcome(prompt, outcome),
+		}, discordgo.WithContext(ctx))
+	})
+	defer rs.router.UnregisterComponent(customID)
+
+	err := rs.Respond(ctx, &discordgo.InteractionResponseData{
+		Content: prompt,
+		Flags:   discordgo.MessageFlagsEphemeral,
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.Button{Label: c.confirmLabel, Style: discordgo.DangerButton, CustomID: customID + ":confirm"},
+				discordgo.Button{Label: c.cancelLabel, Style: discordgo.SecondaryButton, CustomID: customID + ":cancel"},
+			}},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	outcome := "Timed out"
+	select {
+	case confirmed := <-choices:
+		return confirmed, nil
+	case <-timer.C:
+		err = ErrConfirmTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+		outcome = "Cancelled"
+	}
+
+	// the context may be done, but the prompt should still be updated
+	if editErr := rs.Edit(context.WithoutCancel(ctx), confirmOutcome(prompt, outcome)); editErr != nil {
+		err = errors.Join(err, editErr)
+	}
+
+	return false, err
+}
+
+func confirmOutcome(prompt, outcome string) *discordgo.InteractionResponseData {
+	return &discordgo.InteractionResponseData{
+		Content:    prompt + "\n**" + outcome + "**",
+		Components: []discordgo.MessageComponent{},
+	}
+}
+
+// nonce returns a random string for use in custom IDs
+func nonce() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,60 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestConfirm(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_handler_which_asks_for_confirmation_is_registered_for_command("foo", time.Minute).and().
+		the_router_is_called_for_command_by("foo", "user")
+
+	then.
+		the_last_response_should_be(discordgo.InteractionResponseChannelMessageWithSource, "Are you sure?").and().
+		the_last_response_should_be_ephemeral()
+
+	when.
+		the_button_is_clicked_by("Confirm", "other")
+
+	then.
+		the_last_response_should_be(discordgo.InteractionResponseChannelMessageWithSource, "This prompt can only be answered by the user who triggered it")
+
+	when.
+		the_button_is_clicked_by("Confirm", "user")
+
+	then.
+		the_confirmation_should_return(true, nil).and().
+		the_last_response_should_be(discordgo.InteractionResponseUpdateMessage, "Are you sure?\n**Confirmed**")
+}
+
+func TestConfirm_Cancel(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_handler_which_asks_for_confirmation_is_registered_for_command("foo", time.Minute).and().
+		the_router_is_called_for_command_by("foo", "user")
+
+	when.
+		the_button_is_clicked_by("Cancel", "user")
+
+	then.
+		the_confirmation_should_return(false, nil).and().
+		the_last_response_should_be(discordgo.InteractionResponseUpdateMessage, "Are you sure?\n**Cancelled**")
+}
+
+func TestConfirm_Timeout(t *testing.T) {
+	given, _, then := NewRouterStage(t)
+
+	given.
+		a_handler_which_asks_for_confirmation_is_registered_for_command("foo", 10*time.Millisecond).and().
+		the_router_is_called_for_command_by("foo", "user")
+
+	then.
+		the_confirmation_should_return(false, ErrConfirmTimeout).and().
+		the_response_should_be_edited()
+}
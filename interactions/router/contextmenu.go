@@ -0,0 +1,69 @@
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// UserCommandHandler handles a user context menu command, receiving the user it was invoked on. The member is nil
+// when the command was not invoked in a guild.
+type UserCommandHandler func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, user *discordgo.User, member *discordgo.Member) (err error)
+
+// MessageCommandHandler handles a message context menu command, receiving the message it was invoked on.
+type MessageCommandHandler func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, message *discordgo.Message) (err error)
+
+// RegisterUserCommand registers a handler for the user context menu command.
+func (r *Router) RegisterUserCommand(name string, handler UserCommandHandler, opts ...RouteOption) {
+	r.RegisterCommand(name, discordgo.UserApplicationCommand, UserCommand(handler), opts...)
+}
+
+// RegisterMessageCommand registers a handler for the message context menu command.
+func (r *Router) RegisterMessageCommand(name string, handler MessageCommandHandler, opts ...RouteOption) {
+	r.RegisterCommand(name, discordgo.MessageApplicationCommand, MessageCommand(handler), opts...)
+}
+
+// UserCommand adapts the handler to an ApplicationCommandHandler, resolving the command's target user and member,
+// e.g. to register it with the bot's builder.
+func UserCommand(handler UserCommandHandler) ApplicationCommandHandler {
+	return func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+		resolved := data.Resolved
+		if resolved == nil {
+			resolved = &discordgo.ApplicationCommandInteractionDataResolved{}
+		}
+
+		member, _ := resolveMember(s, i.GuildID, resolved, data.TargetID)
+
+		user, ok := resolved.Users[data.TargetID]
+		if !ok && member != nil {
+			user, ok = member.User, member.User != nil
+		}
+		if !ok {
+			return fmt.Errorf("target user %q of command %q not resolved", data.TargetID, data.Name)
+		}
+
+		return handler(ctx, s, i, user, member)
+	}
+}
+
+// MessageCommand adapts the handler to an ApplicationCommandHandler, resolving the command's target message, e.g. to
+// register it with the bot's builder.
+func MessageCommand(handler MessageCommandHandler) ApplicationCommandHandler {
+	return func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+		var message *discordgo.Message
+		if data.Resolved != nil {
+			message = data.Resolved.Messages[data.TargetID]
+		}
+
+		if message == nil && s != nil && s.State != nil {
+			message, _ = s.State.Message(i.ChannelID, data.TargetID)
+		}
+
+		if message == nil {
+			return fmt.Errorf("target message %q of command %q not resolved", data.TargetID, data.Name)
+		}
+
+		return handler(ctx, s, i, message)
+	}
+}
@@ -0,0 +1,66 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestRouter_RegisterUserCommand(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_user_command_handler_is_registered("Profile")
+
+	when.
+		the_router_is_called_for_context_menu_command("Profile", discordgo.UserApplicationCommand, "guild", &discordgo.ApplicationCommandInteractionDataResolved{
+			Users:   map[string]*discordgo.User{"target": {ID: "target", Username: "bob"}},
+			Members: map[string]*discordgo.Member{"target": {Nick: "bobby"}},
+		})
+
+	then.
+		the_handler_should_have_received_target("bob (bobby)")
+}
+
+func TestRouter_RegisterUserCommand_OutsideGuild(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_user_command_handler_is_registered("Profile")
+
+	when.
+		the_router_is_called_for_context_menu_command("Profile", discordgo.UserApplicationCommand, "", &discordgo.ApplicationCommandInteractionDataResolved{
+			Users: map[string]*discordgo.User{"target": {ID: "target", Username: "bob"}},
+		})
+
+	then.
+		the_handler_should_have_received_target("bob")
+}
+
+func TestRouter_RegisterMessageCommand(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_message_command_handler_is_registered("Quote")
+
+	when.
+		the_router_is_called_for_context_menu_command("Quote", discordgo.MessageApplicationCommand, "guild", &discordgo.ApplicationCommandInteractionDataResolved{
+			Messages: map[string]*discordgo.Message{"target": {ID: "target", Content: "hello"}},
+		})
+
+	then.
+		the_handler_should_have_received_target("hello")
+}
+
+func TestRouter_RegisterMessageCommand_Unresolved(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_message_command_handler_is_registered("Quote")
+
+	when.
+		the_router_is_called_for_context_menu_command("Quote", discordgo.MessageApplicationCommand, "guild", nil)
+
+	then.
+		the_handler_should_have_been_called_n_times(0)
+}
@@ -0,0 +1,75 @@
+package router
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SeenStore records the IDs of interactions handled by the router, so that repeated deliveries of the same interaction
+// (e.g. after a gateway resume or a retried HTTP request) can be dropped. Implementations backed by shared storage
+// allow multiple replicas to deduplicate interactions between them.
+type SeenStore interface {
+	// MarkSeen records the interaction ID, reporting whether it had already been recorded
+	MarkSeen(ctx context.Context, id string) (seen bool, err error)
+}
+
+// WithDeduplication drops interactions whose IDs have already been recorded in the store.
+func WithDeduplication(store SeenStore) Option {
+	return func(r *Router) {
+		r.seen = store
+	}
+}
+
+// MemoryStore is an in-memory SeenStore which records interaction IDs for a TTL, holding at most a maximum number of
+// IDs at once.
+type MemoryStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	expires map[string]time.Time
+	// ids holds the recorded IDs in the order they were recorded, and therefore in the order they expire
+	ids []string
+	now func() time.Time
+}
+
+// NewMemoryStore creates a MemoryStore which records up to size interaction IDs for the ttl. Interactions can only be
+// acknowledged within 15 minutes of being created, so a longer TTL is unnecessary.
+func NewMemoryStore(size int, ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		ttl:     ttl,
+		size:    size,
+		expires: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+func (m *MemoryStore) MarkSeen(_ context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+
+	for len(m.ids) > 0 && !m.expires[m.ids[0]].After(now) {
+		m.evict()
+	}
+
+	if _, ok := m.expires[id]; ok {
+		return true, nil
+	}
+
+	for len(m.ids) > 0 && len(m.ids) >= m.size {
+		m.evict()
+	}
+
+	m.expires[id] = now.Add(m.ttl)
+	m.ids = append(m.ids, id)
+
+	return false, nil
+}
+
+// evict removes the oldest ID
+func (m *MemoryStore) evict() {
+	delete(m.expires, m.ids[0])
+	m.ids = m.ids[1:]
+}
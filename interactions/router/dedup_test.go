@@ -0,0 +1,36 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_MarkSeen(t *testing.T) {
+	now := time.Now()
+
+	m := NewMemoryStore(2, time.Minute)
+	m.now = func() time.Time { return now }
+
+	seen := func(id string) bool {
+		seen, err := m.MarkSeen(context.Background(), id)
+		require.NoError(t, err)
+
+		return seen
+	}
+
+	require.False(t, seen("a"))
+	require.True(t, seen("a"))
+	require.False(t, seen("b"))
+
+	// evicts the oldest ID when full
+	require.False(t, seen("c"))
+	require.False(t, seen("a"))
+
+	// evicts expired IDs
+	now = now.Add(time.Minute)
+	require.False(t, seen("c"))
+	require.Len(t, m.ids, 1)
+}
@@ -0,0 +1,145 @@
+package router
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/report"
+)
+
+// RequireSKU restricts the command to users or guilds entitled to any of the SKUs, as reported by the interaction's
+// entitlements. Invocations without an active entitlement are answered with an ephemeral upsell with a premium button
+// for the first SKU, instead of reaching the handler.
+func RequireSKU(skuIDs ...string) RouteOption {
+	return func(r *commandRoute) {
+		r.skus = skuIDs
+	}
+}
+
+// entitled reports whether the interaction's entitlements grant any of the route's SKUs
+func (r commandRoute) entitled(i *discordgo.Interaction) bool {
+	return len(r.skus) == 0 || Entitled(i, r.skus...)
+}
+
+// Entitled reports whether the interaction's entitlements grant any of the SKUs.
+func Entitled(i *discordgo.Interaction, skuIDs ...string) bool {
+	now := time.Now()
+	for _, e := range i.Entitlements {
+		if slices.Contains(skuIDs, e.SKUID) && activeEntitlement(e, now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// activeEntitlement reports whether the entitlement currently grants access to its SKU
+func activeEntitlement(e *discordgo.Entitlement, now time.Time) bool {
+	switch {
+	case e.Deleted:
+		return false
+	case e.Consumed != nil && *e.Consumed:
+		return false
+	case e.StartsAt != nil && now.Before(*e.StartsAt):
+		return false
+	case e.EndsAt != nil && !now.Before(*e.EndsAt):
+		return false
+	default:
+		return true
+	}
+}
+
+// premiumRequired is the upsell sent when the user is not entitled to the route's SKUs
+func premiumRequired(skuID string) *discordgo.InteractionResponseData {
+	return &discordgo.InteractionResponseData{
+		Content: "This command requires a premium subscription",
+		Flags:   discordgo.MessageFlagsEphemeral,
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.Button{Style: discordgo.PremiumButton, SKUID: skuID},
+			}},
+		},
+	}
+}
+
+// EntitlementEvent is the change made to an entitlement.
+type EntitlementEvent int
+
+const (
+	// EntitlementCreated is sent when a user subscribes to or purchases a SKU
+	EntitlementCreated EntitlementEvent = iota
+	// EntitlementUpdated is sent when a subscription renews or is cancelled, setting its end date
+	EntitlementUpdated
+	// EntitlementDeleted is sent when an entitlement is refunded or removed. Entitlements are not deleted when they end.
+	EntitlementDeleted
+)
+
+type EntitlementHandler func(ctx context.Context, s *discordgo.Session, event EntitlementEvent, e *discordgo.Entitlement) (err error)
+
+// RegisterEntitlementHandler registers a handler for entitlement events, called in the order handlers are registered.
+// The router's HandleEntitlementCreate, HandleEntitlementUpdate and HandleEntitlementDelete methods must be added to
+// the session as handlers, which the bot does automatically.
+func (r *Router) RegisterEntitlementHandler(handler EntitlementHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entitlementHandlers = append(r.entitlementHandlers, handler)
+}
+
+// HandleEntitlementCreate implements the discordgo.EntitlementCreate handler.
+func (r *Router) HandleEntitlementCreate(s *discordgo.Session, e *discordgo.EntitlementCreate) {
+	r.handleEntitlement(context.Background(), s, EntitlementCreated, e.Entitlement)
+}
+
+// HandleEntitlementUpdate implements the discordgo.EntitlementUpdate handler.
+func (r *Router) HandleEntitlementUpdate(s *discordgo.Session, e *discordgo.EntitlementUpdate) {
+	r.handleEntitlement(context.Background(), s, EntitlementUpdated, e.Entitlement)
+}
+
+// HandleEntitlementDelete implements the discordgo.EntitlementDelete handler.
+func (r *Router) HandleEntitlementDelete(s *discordgo.Session, e *discordgo.EntitlementDelete) {
+	r.handleEntitlement(context.Background(), s, EntitlementDeleted, e.Entitlement)
+}
+
+func (r *Router) handleEntitlement(ctx context.Context, s *discordgo.Session, event EntitlementEvent, e *discordgo.Entitlement) {
+	if e == nil {
+		return
+	}
+
+	log := r.log.With(
+		slog.String("entitlement", e.ID),
+		slog.String("sku", e.SKUID),
+	)
+
+	r.mu.RLock()
+	handlers := slices.Clone(r.entitlementHandlers)
+	r.mu.RUnlock()
+
+	for _, h := range handlers {
+		if err := callEntitlementHandler(ctx, s, h, event, e); err != nil {
+			log.Error("Failed to handle entitlement", "error", err)
+
+			r.report(ctx, report.Report{
+				Err:     err,
+				Source:  "entitlement",
+				Route:   e.SKUID,
+				UserID:  e.UserID,
+				GuildID: e.GuildID,
+			})
+		}
+	}
+}
+
+// callEntitlementHandler calls the handler, returning any panic as a *report.PanicError
+func callEntitlementHandler(ctx context.Context, s *discordgo.Session, h EntitlementHandler, event EntitlementEvent, e *discordgo.Entitlement) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = report.Recovered(v)
+		}
+	}()
+
+	return h(ctx, s, event, e)
+}
@@ -0,0 +1,60 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestRouter_RequireSKU(t *testing.T) {
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	consumed := true
+
+	tests := map[string]struct {
+		entitlements []*discordgo.Entitlement
+		entitled     bool
+	}{
+		"no entitlements":      {},
+		"other sku":            {entitlements: []*discordgo.Entitlement{{SKUID: "other"}}},
+		"entitled":             {entitlements: []*discordgo.Entitlement{{SKUID: "sku"}}, entitled: true},
+		"subscription active":  {entitlements: []*discordgo.Entitlement{{SKUID: "sku", StartsAt: &past, EndsAt: &future}}, entitled: true},
+		"subscription ended":   {entitlements: []*discordgo.Entitlement{{SKUID: "sku", StartsAt: &past, EndsAt: &past}}},
+		"subscription pending": {entitlements: []*discordgo.Entitlement{{SKUID: "sku", StartsAt: &future}}},
+		"deleted":              {entitlements: []*discordgo.Entitlement{{SKUID: "sku", Deleted: true}}},
+		"consumed":             {entitlements: []*discordgo.Entitlement{{SKUID: "sku", Consumed: &consumed}}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			given, when, then := NewRouterStage(t)
+
+			given.
+				a_handler_is_registered_for_command_requiring_sku("foo", "sku")
+
+			when.
+				the_router_is_called_for_command_with_entitlements("foo", tt.entitlements...)
+
+			if tt.entitled {
+				then.the_handler_should_have_been_called_n_times(1)
+			} else {
+				then.
+					the_last_response_should_upsell_sku("sku").and().
+					the_handler_should_have_been_called_n_times(0)
+			}
+		})
+	}
+}
+
+func TestRouter_EntitlementHandler(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		an_entitlement_handler_is_registered()
+
+	when.
+		an_entitlement_is_created_updated_and_deleted(&discordgo.Entitlement{ID: "entitlement", SKUID: "sku"})
+
+	then.
+		the_entitlement_events_should_be(EntitlementCreated, EntitlementUpdated, EntitlementDeleted)
+}
@@ -0,0 +1,71 @@
+package router
+
+import (
+	"context"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// acknowledged reports whether the router has already sent a deferred response to the interaction
+func (r *Router) acknowledged(i *discordgo.InteractionCreate) bool {
+	return r.deferredResponseEnabled && i.Type == discordgo.InteractionApplicationCommand
+}
+
+// UserID returns the ID of the user who triggered the interaction, in a guild or otherwise.
+func UserID(i *discordgo.Interaction) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+
+	if i.User != nil {
+		return i.User.ID
+	}
+
+	return ""
+}
+
+func respondEphemeral(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, content string) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	}, discordgo.WithContext(ctx))
+}
+
+// disableMessageComponents disables the buttons and select menus of the interaction's original response or follow-up
+// message with the ID
+func disableMessageComponents(s *discordgo.Session, i *discordgo.Interaction, messageID string) error {
+	m, err := s.WebhookMessage(i.AppID, i.Token, messageID)
+	if err != nil {
+		return err
+	}
+
+	components := disableComponents(m.Components)
+
+	_, err = s.WebhookMessageEdit(i.AppID, i.Token, messageID, &discordgo.WebhookEdit{Components: &components})
+
+	return err
+}
+
+func disableComponents(components []discordgo.MessageComponent) []discordgo.MessageComponent {
+	disabled := make([]discordgo.MessageComponent, 0, len(components))
+
+	for _, c := range components {
+		switch c := c.(type) {
+		case *discordgo.ActionsRow:
+			disabled = append(disabled, discordgo.ActionsRow{Components: disableComponents(c.Components)})
+		case *discordgo.Button:
+			c.Disabled = true
+			disabled = append(disabled, c)
+		case *discordgo.SelectMenu:
+			c.Disabled = true
+			disabled = append(disabled, c)
+		default:
+			disabled = append(disabled, c)
+		}
+	}
+
+	return disabled
+}
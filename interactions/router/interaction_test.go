@@ -0,0 +1,14 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserID(t *testing.T) {
+	require.Equal(t, "member", UserID(&discordgo.Interaction{Member: &discordgo.Member{User: &discordgo.User{ID: "member"}}}))
+	require.Equal(t, "user", UserID(&discordgo.Interaction{User: &discordgo.User{ID: "user"}}))
+	require.Empty(t, UserID(&discordgo.Interaction{}))
+}
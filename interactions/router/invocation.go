@@ -0,0 +1,107 @@
+package router
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Invocation describes where an interaction was triggered from, and the installations of the app which authorized it.
+type Invocation struct {
+	// Context is where the interaction was triggered from
+	Context discordgo.InteractionContextType
+	// GuildInstallID is the ID of the guild the app is installed to when authorized by a guild install, or "0" when
+	// triggered in a DM with the bot
+	GuildInstallID string
+	// UserInstallID is the ID of the user the app is installed to when authorized by a user install
+	UserInstallID string
+}
+
+// InvocationOf describes where the interaction was triggered from.
+func InvocationOf(i *discordgo.Interaction) Invocation {
+	return Invocation{
+		Context:        i.Context,
+		GuildInstallID: i.AuthorizingIntegrationOwners[discordgo.ApplicationIntegrationGuildInstall],
+		UserInstallID:  i.AuthorizingIntegrationOwners[discordgo.ApplicationIntegrationUserInstall],
+	}
+}
+
+// GuildInstall reports whether the interaction was authorized by the app's installation to a guild (or was triggered
+// in a DM with the bot).
+func (v Invocation) GuildInstall() bool {
+	return v.GuildInstallID != ""
+}
+
+// UserInstall reports whether the interaction was authorized by the app's installation to a user. Interactions may be
+// authorized by both a guild and a user install.
+func (v Invocation) UserInstall() bool {
+	return v.UserInstallID != ""
+}
+
+// InGuild reports whether the interaction was triggered in a guild.
+func (v Invocation) InGuild() bool {
+	return v.Context == discordgo.InteractionContextGuild
+}
+
+// InBotDM reports whether the interaction was triggered in a DM with the bot.
+func (v Invocation) InBotDM() bool {
+	return v.Context == discordgo.InteractionContextBotDM
+}
+
+// InPrivateChannel reports whether the interaction was triggered in a group DM or a DM other than with the bot, which
+// requires the app to be installed to the user.
+func (v Invocation) InPrivateChannel() bool {
+	return v.Context == discordgo.InteractionContextPrivateChannel
+}
+
+// RouteOption configures a route as it is registered.
+type RouteOption func(*commandRoute)
+
+// WithContexts restricts the command to the contexts, matching the command's declared Contexts. Invocations from other
+// contexts (e.g. from a command which was migrated before its contexts were changed) are answered with an ephemeral
+// error instead of reaching the handler.
+func WithContexts(contexts ...discordgo.InteractionContextType) RouteOption {
+	return func(r *commandRoute) {
+		r.contexts = contexts
+	}
+}
+
+// commandRoute is an application command handler and the restrictions on its invocation
+type commandRoute struct {
+	handler  ApplicationCommandHandler
+	contexts []discordgo.InteractionContextType
+	skus     []string
+	rules    []Rule
+	// variants are alternative handlers invocations are routed between, bucketed by bucketing
+	variants  []Variant
+	bucketing Bucketing
+	// middleware is the middleware of the sub-routers the route was mounted from, outermost first, followed by the
+	// route's own middleware
+	middleware []Middleware
+}
+
+// allows reports whether the command can be invoked in the context
+func (r commandRoute) allows(context discordgo.InteractionContextType) bool {
+	return r.contexts == nil || slices.Contains(r.contexts, context)
+}
+
+var contextNames = map[discordgo.InteractionContextType]string{
+	discordgo.InteractionContextGuild:          "servers",
+	discordgo.InteractionContextBotDM:          "DMs with the bot",
+	discordgo.InteractionContextPrivateChannel: "group DMs and DMs with other users",
+}
+
+// contextError describes where a command can be used
+func contextError(contexts []discordgo.InteractionContextType) string {
+	if len(contexts) == 0 {
+		return "This command can not be used here"
+	}
+
+	names := make([]string, 0, len(contexts))
+	for _, c := range contexts {
+		names = append(names, contextNames[c])
+	}
+
+	return "This command can only be used in " + strings.Join(names, " or ")
+}
@@ -0,0 +1,47 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvocationOf(t *testing.T) {
+	i := &discordgo.Interaction{
+		Context: discordgo.InteractionContextPrivateChannel,
+		AuthorizingIntegrationOwners: map[discordgo.ApplicationIntegrationType]string{
+			discordgo.ApplicationIntegrationUserInstall: "user",
+		},
+	}
+
+	v := InvocationOf(i)
+
+	require.True(t, v.UserInstall())
+	require.Equal(t, "user", v.UserInstallID)
+	require.False(t, v.GuildInstall())
+	require.True(t, v.InPrivateChannel())
+	require.False(t, v.InGuild())
+	require.False(t, v.InBotDM())
+}
+
+func TestRouter_ApplicationCommand_Contexts(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_handler_is_registered_for_command_in_contexts("foo", discordgo.InteractionContextGuild, discordgo.InteractionContextBotDM)
+
+	when.
+		the_router_is_called_for_command_in_context("foo", discordgo.InteractionContextBotDM)
+
+	then.
+		the_handler_should_have_been_called_n_times(1)
+
+	when.
+		the_router_is_called_for_command_in_context("foo", discordgo.InteractionContextPrivateChannel)
+
+	then.
+		the_last_response_should_be(discordgo.InteractionResponseChannelMessageWithSource, "This command can only be used in servers or DMs with the bot").and().
+		the_last_response_should_be_ephemeral().and().
+		the_handler_should_have_been_called_n_times(1)
+}
@@ -0,0 +1,60 @@
+package router
+
+import (
+	"context"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/report"
+)
+
+// HandlerFunc handles an interaction which the router has matched to a route.
+type HandlerFunc func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) (err error)
+
+// Middleware wraps the handling of interactions matched to a router's routes, e.g. to log, authorise or instrument
+// them. Middleware may handle the interaction itself instead of calling next.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Use adds middleware to the router, which wraps every route of the router in the order the middleware is added.
+// Middleware is called after the route has been matched, so routes which are not matched (and interactions sent to
+// collectors) are not passed to middleware.
+func (r *Router) Use(middleware ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// WithMiddleware wraps the command's handler in the middleware, inside the router's middleware, e.g. to gate a single
+// command.
+func WithMiddleware(middleware ...Middleware) RouteOption {
+	return func(r *commandRoute) {
+		r.middleware = append(r.middleware, middleware...)
+	}
+}
+
+// handlerRoute is a handler and the middleware of the sub-routers it was mounted from, outermost first
+type handlerRoute[H any] struct {
+	handler    H
+	middleware []Middleware
+}
+
+// run calls the handler wrapped in the router's middleware and the route's middleware, returning any panic as a
+// *report.PanicError
+func (r *Router) run(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, middleware []Middleware, h HandlerFunc) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = report.Recovered(v)
+		}
+	}()
+
+	r.mu.RLock()
+	chain := append(slices.Clone(r.middleware), middleware...)
+	r.mu.RUnlock()
+
+	for _, m := range slices.Backward(chain) {
+		h = m(h)
+	}
+
+	return h(ctx, s, i)
+}
@@ -0,0 +1,51 @@
+package router
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type ModalSubmitHandler func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ModalSubmitInteractionData) (err error)
+
+// RegisterModal registers a handler for modal submissions with the custom ID. As with RegisterComponent, the handler
+// is also called for modals whose custom IDs extend the custom ID with ":" separated values.
+func (r *Router) RegisterModal(customID string, handler ModalSubmitHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.modalHandlers[customID] = handlerRoute[ModalSubmitHandler]{handler: handler}
+}
+
+// UnregisterModal removes the handler for the modal custom ID, if registered.
+func (r *Router) UnregisterModal(customID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.modalHandlers, customID)
+}
+
+func (r *Router) handleModalSubmit(ctx context.Context, s *discordgo.Session, e *discordgo.InteractionCreate) {
+	modal := e.ModalSubmitData()
+
+	log := r.log.With(
+		slog.String("interaction", e.ID),
+		slog.String("custom_id", modal.CustomID),
+	)
+
+	r.mu.RLock()
+	route, ok := lookupCustomID(r.modalHandlers, modal.CustomID)
+	r.mu.RUnlock()
+	if !ok {
+		log.Error("Handler not found for modal submit")
+		return
+	}
+
+	err := r.run(ctx, s, e, route.middleware, func(ctx context.Context, s *discordgo.Session, e *discordgo.InteractionCreate) error {
+		return route.handler(ctx, s, e, modal)
+	})
+	if err != nil {
+		r.fail(ctx, log, e, modal.CustomID, err)
+	}
+}
@@ -0,0 +1,106 @@
+package router
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ErrRouteConflict is returned by Mount when a sub-router has a route which is already registered with the router.
+var ErrRouteConflict = errors.New("route conflict")
+
+// Mount merges the routes of the sub-router into the router, wrapping them in the sub-router's middleware (inside the
+// router's own middleware), and adds the sub-router's entitlement handlers. If any route is already registered with
+// the router, no routes are merged and every conflict is returned.
+//
+// The sub-router's routes and middleware are copied when it is mounted, so later changes to the sub-router do not
+// affect the router. Options of the sub-router, such as deferred responses, are not applied to its routes.
+func (r *Router) Mount(sub *Router) error {
+	if sub == r {
+		return fmt.Errorf("%w: a router can not be mounted to itself", ErrRouteConflict)
+	}
+
+	sub.mu.RLock()
+	defer sub.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.conflicts(sub); err != nil {
+		return err
+	}
+
+	for k, route := range sub.applicationCommandHandlers {
+		route.middleware = mountedMiddleware(sub.middleware, route.middleware)
+		r.applicationCommandHandlers[k] = route
+	}
+
+	mountRoutes(r.componentHandlers, sub.componentHandlers, sub.middleware)
+	mountRoutes(r.modalHandlers, sub.modalHandlers, sub.middleware)
+	mountRoutes(r.autocompleteHandlers, sub.autocompleteHandlers, sub.middleware)
+
+	r.entitlementHandlers = append(r.entitlementHandlers, sub.entitlementHandlers...)
+
+	return nil
+}
+
+// Group creates a sub-router, which fn registers routes and middleware with, and mounts it to the router. This allows
+// middleware to be applied to a group of routes.
+func (r *Router) Group(fn func(g *Router)) error {
+	g := New(WithLogger(r.log))
+
+	fn(g)
+
+	return r.Mount(g)
+}
+
+// conflicts returns an error for each of the sub-router's routes which is already registered with the router
+func (r *Router) conflicts(sub *Router) error {
+	var errs []error
+
+	for _, k := range slices.SortedFunc(maps.Keys(sub.applicationCommandHandlers), compareKeys) {
+		if _, ok := r.applicationCommandHandlers[k]; ok {
+			errs = append(errs, fmt.Errorf("%w: application command %q of type %d", ErrRouteConflict, k.name, k.commandType))
+		}
+	}
+
+	errs = append(errs, conflicts("autocomplete", r.autocompleteHandlers, sub.autocompleteHandlers)...)
+	errs = append(errs, conflicts("message component", r.componentHandlers, sub.componentHandlers)...)
+	errs = append(errs, conflicts("modal", r.modalHandlers, sub.modalHandlers)...)
+
+	return errors.Join(errs...)
+}
+
+func conflicts[H any](kind string, routes, sub map[string]handlerRoute[H]) []error {
+	var errs []error
+
+	for _, name := range slices.Sorted(maps.Keys(sub)) {
+		if _, ok := routes[name]; ok {
+			errs = append(errs, fmt.Errorf("%w: %s %q", ErrRouteConflict, kind, name))
+		}
+	}
+
+	return errs
+}
+
+func mountRoutes[H any](routes, sub map[string]handlerRoute[H], middleware []Middleware) {
+	for name, route := range sub {
+		route.middleware = mountedMiddleware(middleware, route.middleware)
+		routes[name] = route
+	}
+}
+
+// mountedMiddleware returns the middleware of a mounted route: the sub-router's middleware followed by the middleware
+// the route was mounted to the sub-router with
+func mountedMiddleware(sub, route []Middleware) []Middleware {
+	return append(slices.Clone(sub), route...)
+}
+
+func compareKeys(a, b key) int {
+	return cmp.Or(
+		cmp.Compare(a.name, b.name),
+		cmp.Compare(a.commandType, b.commandType),
+	)
+}
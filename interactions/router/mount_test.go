@@ -0,0 +1,118 @@
+package router
+
+import (
+	"testing"
+)
+
+func TestRouter_Use(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		middleware_is_used("first", "second").and().
+		a_handler_is_registered_for_command("foo")
+
+	when.
+		the_router_is_called_for_command("foo")
+
+	then.
+		the_middleware_should_have_been_called("first", "second").and().
+		the_handler_should_have_been_called_n_times(1)
+}
+
+func TestRouter_Use_NotFound(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		middleware_is_used("first")
+
+	when.
+		the_router_is_called_for_command("foo")
+
+	then.
+		the_middleware_should_have_been_called()
+}
+
+func TestRouter_WithMiddleware(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		middleware_is_used("router").and().
+		a_handler_is_registered_for_command_with_middleware("foo", "route").and().
+		a_handler_is_registered_for_command("bar")
+
+	when.
+		the_router_is_called_for_command("foo")
+
+	when.
+		the_router_is_called_for_command("bar")
+
+	then.
+		the_middleware_should_have_been_called("router", "route", "router").and().
+		the_handler_should_have_been_called_n_times(2)
+}
+
+func TestRouter_Mount(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		middleware_is_used("parent").and().
+		a_handler_is_registered_for_command("foo").and().
+		a_sub_router_with_middleware("sub").and().
+		the_sub_router_has_a_command("bar").and().
+		the_sub_router_has_a_component("baz").and().
+		the_sub_router_is_mounted()
+
+	when.
+		the_router_is_called_for_command("foo")
+
+	then.
+		no_error_should_be_returned().and().
+		the_middleware_should_have_been_called("parent")
+
+	when.
+		the_router_is_called_for_command("bar")
+
+	when.
+		the_router_is_called_for_component("baz:1")
+
+	then.
+		the_middleware_should_have_been_called("parent", "parent", "sub", "parent", "sub").and().
+		the_handler_should_have_been_called_n_times(3)
+}
+
+func TestRouter_Mount_Conflict(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_handler_is_registered_for_command("foo").and().
+		a_sub_router_with_middleware("sub").and().
+		the_sub_router_has_a_command("foo").and().
+		the_sub_router_has_a_command("bar")
+
+	when.
+		the_sub_router_is_mounted()
+
+	then.
+		the_error_should_be(ErrRouteConflict, `application command "foo"`)
+
+	when.
+		the_router_is_called_for_command("bar")
+
+	then.
+		the_handler_should_have_been_called_n_times(0)
+}
+
+func TestRouter_Group(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_group_with_middleware_and_command("admin", "ban")
+
+	when.
+		the_router_is_called_for_command("ban")
+
+	then.
+		no_error_should_be_returned().and().
+		the_middleware_should_have_been_called("admin").and().
+		the_handler_should_have_been_called_n_times(1)
+}
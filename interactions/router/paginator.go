@@ -0,0 +1,204 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	paginatorPrefix         = "paginator"
+	defaultPaginatorTimeout = 5 * time.Minute
+	noPagesMessage          = "There is nothing to show"
+)
+
+// PageCount returns the number of pages available to the user who triggered the interaction. When there are no pages,
+// the paginator responds with a message saying so instead of rendering a page.
+type PageCount func(ctx context.Context, i *discordgo.InteractionCreate) (int, error)
+
+// PageRenderer renders the page (starting from 0) for the user who triggered the interaction. The paginator's buttons
+// are appended to the page's components.
+type PageRenderer func(ctx context.Context, i *discordgo.InteractionCreate, page int) (*discordgo.InteractionResponseData, error)
+
+// Paginator responds to interactions with pages of results, which the invoking user can navigate with previous and
+// next buttons. The current page is encoded in the buttons' custom IDs, so no state is held between interactions.
+type Paginator struct {
+	router  *Router
+	name    string
+	count   PageCount
+	render  PageRenderer
+	timeout time.Duration
+}
+
+type PaginatorOption func(*Paginator)
+
+// WithPaginatorTimeout sets how long the paginator's buttons can be used for, after which they are disabled. As
+// interaction tokens expire after 15 minutes, the buttons can not be disabled after longer timeouts.
+func WithPaginatorTimeout(d time.Duration) PaginatorOption {
+	return func(p *Paginator) {
+		p.timeout = d
+	}
+}
+
+// NewPaginator creates a Paginator, registering a component route for its buttons. The name must be unique among the
+// router's paginators.
+func (r *Router) NewPaginator(name string, count PageCount, render PageRenderer, opts ...PaginatorOption) *Paginator {
+	p := &Paginator{
+		router:  r,
+		name:    name,
+		count:   count,
+		render:  render,
+		timeout: defaultPaginatorTimeout,
+	}
+
+	for _, o := range opts {
+		o(p)
+	}
+
+	r.RegisterComponent(p.customID(), p.handle)
+
+	return p
+}
+
+// Respond responds to the interaction with the first page, and disables the buttons once the timeout has passed. The
+// page is sent with the interaction's Responder, so it fills in a deferred response, or is sent as a follow-up if the
+// interaction has already been responded to.
+func (p *Paginator) Respond(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	state := pageState{
+		user:    UserID(i.Interaction),
+		expires: time.Now().Add(p.timeout),
+	}
+
+	data, err := p.page(ctx, i, state)
+	if err != nil {
+		return err
+	}
+
+	rs := ResponderFromContext(ctx)
+	if rs == nil || rs.interaction.ID != i.ID {
+		rs = p.router.Responder(s, i)
+	}
+
+	messageID, err := rs.respond(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	time.AfterFunc(p.timeout, func() {
+		if err := disableMessageComponents(rs.session, i.Interaction, messageID); err != nil {
+			p.router.log.Error("Failed to disable paginator", slog.String("interaction", i.ID), "error", err)
+		}
+	})
+
+	return nil
+}
+
+func (p *Paginator) handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.MessageComponentInteractionData) error {
+	state, err := p.parse(data.CustomID)
+	if err != nil {
+		return err
+	}
+
+	if state.user != UserID(i.Interaction) {
+		return respondEphemeral(ctx, s, i, "These buttons can only be used by the user who triggered them")
+	}
+
+	if time.Now().After(state.expires) {
+		return respondEphemeral(ctx, s, i, "These buttons have expired")
+	}
+
+	page, err := p.page(ctx, i, state)
+	if err != nil {
+		return err
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: page,
+	}, discordgo.WithContext(ctx))
+}
+
+// page renders the page for the state, appending the navigation buttons
+func (p *Paginator) page(ctx context.Context, i *discordgo.InteractionCreate, state pageState) (*discordgo.InteractionResponseData, error) {
+	n, err := p.count(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+
+	if n <= 0 {
+		// there are no pages to render or navigate between
+		return &discordgo.InteractionResponseData{Content: noPagesMessage}, nil
+	}
+
+	state.page = max(0, min(state.page, n-1))
+
+	data, err := p.render(ctx, i, state.page)
+	if err != nil {
+		return nil, err
+	}
+
+	prev, next := state, state
+	prev.page, next.page = state.page-1, state.page+1
+
+	data.Components = append(data.Components, discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+		discordgo.Button{
+			Label:    "Previous",
+			Style:    discordgo.SecondaryButton,
+			CustomID: p.customID(prev.String(), "prev"),
+			Disabled: state.page == 0,
+		},
+		discordgo.Button{
+			Label:    fmt.Sprintf("%d / %d", state.page+1, max(n, 1)),
+			Style:    discordgo.SecondaryButton,
+			CustomID: p.customID(state.String(), "current"),
+			Disabled: true,
+		},
+		discordgo.Button{
+			Label:    "Next",
+			Style:    discordgo.SecondaryButton,
+			CustomID: p.customID(next.String(), "next"),
+			Disabled: state.page >= n-1,
+		},
+	}})
+
+	return data, nil
+}
+
+func (p *Paginator) customID(values ...string) string {
+	return strings.Join(append([]string{paginatorPrefix, p.name}, values...), ":")
+}
+
+func (p *Paginator) parse(customID string) (pageState, error) {
+	values := strings.Split(strings.TrimPrefix(customID, p.customID()+":"), ":")
+	if len(values) != 4 {
+		return pageState{}, fmt.Errorf("invalid paginator custom ID %q", customID)
+	}
+
+	page, err := strconv.Atoi(values[1])
+	if err != nil {
+		return pageState{}, fmt.Errorf("invalid paginator custom ID %q: %w", customID, err)
+	}
+
+	expires, err := strconv.ParseInt(values[2], 10, 64)
+	if err != nil {
+		return pageState{}, fmt.Errorf("invalid paginator custom ID %q: %w", customID, err)
+	}
+
+	return pageState{user: values[0], page: page, expires: time.Unix(expires, 0)}, nil
+}
+
+// pageState is the state of a paginated message, encoded in its buttons' custom IDs
+type pageState struct {
+	user    string
+	page    int
+	expires time.Time
+}
+
+func (s pageState) String() string {
+	return fmt.Sprintf("%s:%d:%d", s.user, s.page, s.expires.Unix())
+}
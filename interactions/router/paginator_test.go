@@ -0,0 +1,81 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestPaginator(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_paginator_with_n_pages(3, time.Minute).and().
+		the_paginator_responds_to_a_command_from("user")
+
+	then.
+		the_last_response_should_be(discordgo.InteractionResponseChannelMessageWithSource, "Page 1")
+
+	when.
+		the_button_is_clicked_by("Next", "user")
+
+	then.
+		the_last_response_should_be(discordgo.InteractionResponseUpdateMessage, "Page 2")
+
+	when.
+		the_button_is_clicked_by("Next", "user").and().
+		the_button_is_clicked_by("Previous", "user")
+
+	then.
+		the_last_response_should_be(discordgo.InteractionResponseUpdateMessage, "Page 2")
+}
+
+func TestPaginator_OtherUser(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_paginator_with_n_pages(3, time.Minute).and().
+		the_paginator_responds_to_a_command_from("user")
+
+	when.
+		the_button_is_clicked_by("Next", "other")
+
+	then.
+		the_last_response_should_be(discordgo.InteractionResponseChannelMessageWithSource, "These buttons can only be used by the user who triggered them").and().
+		the_last_response_should_be_ephemeral()
+}
+
+func TestPaginator_Timeout(t *testing.T) {
+	given, _, then := NewRouterStage(t)
+
+	given.
+		a_paginator_with_n_pages(3, 10*time.Millisecond).and().
+		the_paginator_responds_to_a_command_from("user")
+
+	then.
+		the_response_should_be_edited()
+}
+
+func TestPaginator_AlreadyResponded(t *testing.T) {
+	given, _, then := NewRouterStage(t)
+
+	given.
+		a_paginator_with_n_pages(3, time.Minute).and().
+		the_paginator_responds_to_a_command_which_has_been_responded_to("user")
+
+	then.
+		the_interaction_should_have_n_responses_and_n_followups(1, 1)
+}
+
+func TestPaginator_NoPages(t *testing.T) {
+	given, _, then := NewRouterStage(t)
+
+	given.
+		a_paginator_with_n_pages(0, time.Minute).and().
+		the_paginator_responds_to_a_command_from("user")
+
+	then.
+		the_last_response_should_be(discordgo.InteractionResponseChannelMessageWithSource, "There is nothing to show").and().
+		the_last_response_should_have_no_components()
+}
@@ -0,0 +1,134 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	promptPrefix = "prompt"
+	// defaultPromptTimeout matches the lifetime of an interaction token. Discord does not report dismissed modals, so
+	// without a timeout a dismissed prompt would wait forever.
+	defaultPromptTimeout = 15 * time.Minute
+)
+
+var (
+	// ErrPromptTimeout is returned by Prompt when the modal is not submitted before the timeout.
+	ErrPromptTimeout = errors.New("prompt timed out")
+	// ErrAlreadyResponded is returned by Prompt when the interaction has already been responded to, as a modal can
+	// only be sent as the initial response.
+	ErrAlreadyResponded = errors.New("interaction has already been responded to")
+)
+
+type promptConfig struct {
+	timeout time.Duration
+}
+
+type PromptOption func(*promptConfig)
+
+// WithPromptTimeout sets how long Prompt waits for the modal to be submitted.
+func WithPromptTimeout(d time.Duration) PromptOption {
+	return func(c *promptConfig) {
+		c.timeout = d
+	}
+}
+
+// Prompt responds to the interaction with the modal, and blocks until it is submitted, the context is done or the
+// timeout passes. The modal's custom ID is generated, so any set is ignored. Prompt returns the values of the modal's
+// text inputs by custom ID, and a Responder for the submit interaction which must be used to respond to it.
+//
+// A modal can only be sent as the initial response, so Prompt can not be used once the interaction has been responded
+// to, or when the router sends deferred responses.
+func (rs *Responder) Prompt(ctx context.Context, modal *discordgo.InteractionResponseData, opts ...PromptOption) (map[string]string, *Responder, error) {
+	c := promptConfig{timeout: defaultPromptTimeout}
+
+	for _, o := range opts {
+		o(&c)
+	}
+
+	type submission struct {
+		values map[string]string
+		rs     *Responder
+	}
+
+	customID := promptPrefix + ":" + nonce()
+	submissions := make(chan submission, 1)
+
+	// register a temporary route for the modal's submission
+	rs.router.RegisterModal(customID, func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ModalSubmitInteractionData) error {
+		submitted := ResponderFromContext(ctx)
+		if submitted == nil {
+			submitted = rs.router.Responder(s, i)
+		}
+
+		select {
+		case submissions <- submission{values: modalValues(data.Components), rs: submitted}:
+		default:
+			// the modal has already been submitted
+		}
+
+		return nil
+	})
+	defer rs.router.UnregisterModal(customID)
+
+	if err := rs.respondModal(ctx, customID, modal); err != nil {
+		return nil, nil, err
+	}
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case s := <-submissions:
+		return s.values, s.rs, nil
+	case <-timer.C:
+		return nil, nil, ErrPromptTimeout
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+func (rs *Responder) respondModal(ctx context.Context, customID string, modal *discordgo.InteractionResponseData) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.deferred || rs.responded {
+		return ErrAlreadyResponded
+	}
+
+	data := *modal
+	data.CustomID = customID
+
+	err := rs.session.InteractionRespond(rs.interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &data,
+	}, discordgo.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	rs.responded = true
+
+	return nil
+}
+
+// modalValues returns the values of the text inputs in the modal's components by custom ID
+func modalValues(components []discordgo.MessageComponent) map[string]string {
+	values := make(map[string]string)
+
+	for _, c := range components {
+		switch c := c.(type) {
+		case *discordgo.ActionsRow:
+			for k, v := range modalValues(c.Components) {
+				values[k] = v
+			}
+		case *discordgo.TextInput:
+			values[c.CustomID] = c.Value
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,48 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestResponder_Prompt(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_handler_which_prompts_for_a_name_is_registered_for_command("foo", time.Minute).and().
+		the_router_is_called_for_command_by("foo", "user")
+
+	when.
+		the_modal_is_submitted_with("name", "Elliot")
+
+	then.
+		the_prompt_should_return(nil).and().
+		the_last_response_should_be(discordgo.InteractionResponseChannelMessageWithSource, "Hello Elliot")
+}
+
+func TestResponder_Prompt_Timeout(t *testing.T) {
+	given, _, then := NewRouterStage(t)
+
+	given.
+		a_handler_which_prompts_for_a_name_is_registered_for_command("foo", 10*time.Millisecond).and().
+		the_router_is_called_for_command_by("foo", "user")
+
+	then.
+		the_prompt_should_return(ErrPromptTimeout)
+}
+
+func TestResponder_Prompt_Deferred(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		deferred_responses_are_enabled().and().
+		a_handler_which_prompts_for_a_name_is_registered_for_command("foo", time.Minute)
+
+	when.
+		the_router_is_called_for_command_by("foo", "user")
+
+	then.
+		the_prompt_should_return(ErrAlreadyResponded)
+}
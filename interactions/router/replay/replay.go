@@ -0,0 +1,322 @@
+// Package replay records interactions handled by a router, and the requests made in reply, as JSON fixtures which can
+// be replayed against the router in tests to catch regressions (see routertest.Replay).
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/interactions/router"
+	pkglog "github.com/elliotwms/bot/log"
+)
+
+const (
+	// RedactedToken replaces the tokens of recorded interactions.
+	RedactedToken = "REDACTED"
+	// maxPending is the number of interactions whose callbacks are kept until their recording starts
+	maxPending = 100
+)
+
+// Fixture is a recorded interaction and the requests made to Discord in reply while it was handled.
+type Fixture struct {
+	Interaction *discordgo.Interaction `json:"interaction"`
+	Requests    []Request              `json:"requests"`
+	// Error is the error returned by the handler, if any
+	Error string `json:"error,omitempty"`
+}
+
+// Request is a request made to Discord, with the interaction's token redacted from its path.
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Body is the JSON body of the request, or the JSON payload of a multipart request (e.g. with files)
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// Recorder records interactions as fixtures in a directory, named by interaction ID. The requests made in reply are
+// recorded by the recorder's Transport, which must be used by the session's HTTP client, while the handler runs: replies
+// sent after the handler returns (e.g. from a goroutine) are not recorded. Deferred responses, which the router sends
+// before calling middleware, are recorded too.
+type Recorder struct {
+	dir       string
+	log       *slog.Logger
+	redactors []func(i *discordgo.Interaction)
+	filter    func(i *discordgo.InteractionCreate) bool
+
+	mu sync.Mutex
+	// recording are the fixtures being recorded, by the token of their interaction
+	recording map[string]*Fixture
+	// pending are the callbacks sent before the interaction's recording started (i.e. deferred responses), by token,
+	// and the order their interactions were seen in
+	pending      map[string][]Request
+	pendingOrder []string
+}
+
+type Option func(*Recorder)
+
+// WithLogger sets the logger used to log fixtures which could not be written.
+func WithLogger(l *slog.Logger) Option {
+	return func(r *Recorder) {
+		r.log = l
+	}
+}
+
+// WithRedactor redacts a copy of each interaction before it is written, in addition to its token, e.g. to remove
+// personal data.
+func WithRedactor(redact func(i *discordgo.Interaction)) Option {
+	return func(r *Recorder) {
+		r.redactors = append(r.redactors, redact)
+	}
+}
+
+// WithFilter records only the interactions the filter returns true for, e.g. to sample them.
+func WithFilter(filter func(i *discordgo.InteractionCreate) bool) Option {
+	return func(r *Recorder) {
+		r.filter = filter
+	}
+}
+
+// NewRecorder returns a recorder which writes fixtures to the directory, creating it if necessary.
+func NewRecorder(dir string, opts ...Option) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	r := &Recorder{
+		dir:       dir,
+		log:       slog.New(pkglog.DiscardHandler),
+		recording: make(map[string]*Fixture),
+		pending:   make(map[string][]Request),
+	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	return r, nil
+}
+
+// Middleware records the interactions handled by the router. Requests are only recorded if the session uses the
+// recorder's Transport.
+func (r *Recorder) Middleware() router.Middleware {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) (err error) {
+			r.mu.Lock()
+			requests := r.pending[i.Token]
+			delete(r.pending, i.Token)
+			r.mu.Unlock()
+
+			if r.filter != nil && !r.filter(i) {
+				return next(ctx, s, i)
+			}
+
+			f := &Fixture{Interaction: r.redact(i.Interaction), Requests: requests}
+
+			r.mu.Lock()
+			r.recording[i.Token] = f
+			r.mu.Unlock()
+
+			defer func() {
+				r.mu.Lock()
+				delete(r.recording, i.Token)
+				r.mu.Unlock()
+
+				if err != nil {
+					f.Error = err.Error()
+				}
+
+				if writeErr := r.write(f); writeErr != nil {
+					r.log.Error("Failed to write fixture", pkglog.WithErr(writeErr), slog.String("interaction", i.ID))
+				}
+			}()
+
+			return next(ctx, s, i)
+		}
+	}
+}
+
+// Transport returns a transport which records the requests made for interactions being recorded before sending them
+// with base, or http.DefaultTransport if nil.
+func (r *Recorder) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if err := r.record(req); err != nil {
+			r.log.Error("Failed to record request", pkglog.WithErr(err), slog.String("path", req.URL.Path))
+		}
+
+		return base.RoundTrip(req)
+	})
+}
+
+// record adds the request to the fixture of the interaction whose token is in its path, or keeps it until the
+// interaction's recording starts if it is an interaction callback
+func (r *Recorder) record(req *http.Request) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	segments := strings.Split(strings.TrimPrefix(req.URL.Path, "/api/v"+discordgo.APIVersion+"/"), "/")
+
+	for token, f := range r.recording {
+		if !slices.Contains(segments, token) {
+			continue
+		}
+
+		body, err := RequestBody(req)
+		if err != nil {
+			return err
+		}
+
+		f.Requests = append(f.Requests, Request{Method: req.Method, Path: RedactPath(req.URL.Path, token), Body: body})
+
+		return nil
+	}
+
+	// interactions/{id}/{token}/callback
+	if len(segments) == 4 && segments[0] == "interactions" && segments[3] == "callback" {
+		body, err := RequestBody(req)
+		if err != nil {
+			return err
+		}
+
+		r.addPending(segments[2], Request{Method: req.Method, Path: RedactPath(req.URL.Path, segments[2]), Body: body})
+	}
+
+	return nil
+}
+
+// addPending keeps the callback until the interaction's recording starts, forgetting the oldest interactions' callbacks
+// (e.g. of interactions without a route) once more than maxPending are kept
+func (r *Recorder) addPending(token string, req Request) {
+	if _, ok := r.pending[token]; !ok {
+		r.pendingOrder = append(r.pendingOrder, token)
+	}
+
+	r.pending[token] = append(r.pending[token], req)
+
+	for len(r.pendingOrder) > maxPending {
+		delete(r.pending, r.pendingOrder[0])
+		r.pendingOrder = r.pendingOrder[1:]
+	}
+}
+
+func (r *Recorder) write(f *Fixture) error {
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(r.dir, filepath.Base(f.Interaction.ID)+".json"), b, 0o600)
+}
+
+// redact returns a copy of the interaction with its token redacted
+func (r *Recorder) redact(i *discordgo.Interaction) *discordgo.Interaction {
+	redacted := *i
+	redacted.Token = RedactedToken
+
+	for _, redact := range r.redactors {
+		redact(&redacted)
+	}
+
+	return &redacted
+}
+
+// RedactPath returns the path of a request relative to Discord's API, with the token replaced with RedactedToken.
+func RedactPath(path, token string) string {
+	path = strings.TrimPrefix(path, "/api/v"+discordgo.APIVersion)
+
+	segments := strings.Split(path, "/")
+	for n, s := range segments {
+		if s == token {
+			segments[n] = RedactedToken
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// RequestBody reads the JSON body of the request, or the JSON payload of a multipart request, restoring the body so
+// that it can be sent.
+func RequestBody(req *http.Request) (json.RawMessage, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		if len(body) == 0 {
+			return nil, nil
+		}
+
+		return body, nil
+	}
+
+	mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if part.FormName() == "payload_json" {
+			return io.ReadAll(part)
+		}
+	}
+}
+
+// Load reads the fixtures in the directory, ordered by file name.
+func Load(dir string) ([]*Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := make([]*Fixture, 0, len(paths))
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		f := &Fixture{}
+		if err := json.Unmarshal(b, f); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		fixtures = append(fixtures, f)
+	}
+
+	return fixtures, nil
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
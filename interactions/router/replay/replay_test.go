@@ -0,0 +1,144 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/interactions/router"
+	"github.com/stretchr/testify/require"
+)
+
+// noContent answers every request with no content instead of sending it to Discord
+var noContent = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Request: req}, nil
+})
+
+func newTestRouter(t *testing.T, rec *Recorder, handler router.ApplicationCommandHandler) (*router.Router, *discordgo.Session) {
+	r := router.New()
+	r.Use(rec.Middleware())
+	r.RegisterCommand("echo", discordgo.ChatApplicationCommand, handler)
+	t.Cleanup(r.Close)
+
+	s, _ := discordgo.New("Bot token")
+	s.Client = &http.Client{Transport: rec.Transport(noContent)}
+
+	return r, s
+}
+
+func command(id string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:    id,
+		AppID: "app",
+		Token: "secret",
+		Type:  discordgo.InteractionApplicationCommand,
+		User:  &discordgo.User{ID: "user", Username: "someone"},
+		Data: discordgo.ApplicationCommandInteractionData{
+			Name:        "echo",
+			CommandType: discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "text", Type: discordgo.ApplicationCommandOptionString, Value: "hi"},
+			},
+		},
+	}}
+}
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+
+	rec, err := NewRecorder(dir, WithRedactor(func(i *discordgo.Interaction) {
+		i.User = &discordgo.User{ID: i.User.ID}
+	}))
+	require.NoError(t, err)
+
+	r, s := newTestRouter(t, rec, func(ctx context.Context, _ *discordgo.Session, _ *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+		return router.ResponderFromContext(ctx).Respond(ctx, &discordgo.InteractionResponseData{
+			Content: data.Options[0].StringValue(),
+		})
+	})
+
+	i := command("1")
+	r.Handle(s, i)
+
+	fixtures, err := Load(dir)
+	require.NoError(t, err)
+	require.Len(t, fixtures, 1)
+
+	f := fixtures[0]
+	require.Equal(t, RedactedToken, f.Interaction.Token)
+	require.Equal(t, "echo", f.Interaction.ApplicationCommandData().Name)
+	require.Empty(t, f.Interaction.User.Username)
+	require.Empty(t, f.Error)
+
+	require.Len(t, f.Requests, 1)
+	require.Equal(t, http.MethodPost, f.Requests[0].Method)
+	require.Equal(t, "/interactions/1/REDACTED/callback", f.Requests[0].Path)
+	require.JSONEq(t, `{"type":4,"data":{"content":"hi","tts":false,"components":null,"embeds":null}}`, string(f.Requests[0].Body))
+
+	// the interaction being handled is not changed
+	require.Equal(t, "secret", i.Token)
+	require.Equal(t, "someone", i.User.Username)
+}
+
+func TestRecorder_Error(t *testing.T) {
+	dir := t.TempDir()
+
+	rec, err := NewRecorder(dir)
+	require.NoError(t, err)
+
+	r, s := newTestRouter(t, rec, func(context.Context, *discordgo.Session, *discordgo.InteractionCreate, discordgo.ApplicationCommandInteractionData) error {
+		return errors.New("oops")
+	})
+
+	r.Handle(s, command("1"))
+
+	fixtures, err := Load(dir)
+	require.NoError(t, err)
+	require.Len(t, fixtures, 1)
+	require.Equal(t, "oops", fixtures[0].Error)
+	require.Empty(t, fixtures[0].Requests)
+}
+
+func TestRecorder_Filter(t *testing.T) {
+	dir := t.TempDir()
+
+	rec, err := NewRecorder(dir, WithFilter(func(i *discordgo.InteractionCreate) bool {
+		return i.ID == "2"
+	}))
+	require.NoError(t, err)
+
+	r, s := newTestRouter(t, rec, func(context.Context, *discordgo.Session, *discordgo.InteractionCreate, discordgo.ApplicationCommandInteractionData) error {
+		return nil
+	})
+
+	r.Handle(s, command("1"))
+	r.Handle(s, command("2"))
+
+	fixtures, err := Load(dir)
+	require.NoError(t, err)
+	require.Len(t, fixtures, 1)
+	require.Equal(t, "2", fixtures[0].Interaction.ID)
+}
+
+func TestRequestBody_Multipart(t *testing.T) {
+	contentType, body, err := discordgo.MultipartBodyWithJSON(map[string]string{"content": "hi"}, []*discordgo.File{
+		{Name: "file.txt", ContentType: "text/plain", Reader: strings.NewReader("file")},
+	})
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodPost, "https://discord.com/api/v10/webhooks/app/token", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", contentType)
+
+	payload, err := RequestBody(req)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"content":"hi"}`, string(payload))
+
+	// the body can still be sent
+	sent, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.Equal(t, body, sent)
+}
@@ -0,0 +1,49 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/report"
+)
+
+// WithReporter reports errors returned by handlers and panics recovered from them, alongside the interaction they
+// occurred while handling.
+func WithReporter(rep report.Reporter) Option {
+	return func(r *Router) {
+		r.reporter = rep
+	}
+}
+
+// fail logs and reports the failure to handle the interaction with the route
+func (r *Router) fail(ctx context.Context, log *slog.Logger, i *discordgo.InteractionCreate, route string, err error) {
+	log.Error("Failed to handle interaction", "error", err)
+
+	r.report(ctx, report.Report{
+		Err:           err,
+		Source:        "interaction",
+		InteractionID: i.ID,
+		Route:         route,
+		UserID:        UserID(i.Interaction),
+		GuildID:       i.GuildID,
+	})
+}
+
+func (r *Router) report(ctx context.Context, rep report.Report) {
+	if r.reporter == nil {
+		return
+	}
+
+	var p *report.PanicError
+	if errors.As(rep.Err, &p) {
+		rep.Stack = p.Stack
+	}
+
+	rep.Time = time.Now()
+
+	// the report should be sent even if the interaction's context is done
+	r.reporter.Report(context.WithoutCancel(ctx), rep)
+}
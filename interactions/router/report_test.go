@@ -0,0 +1,60 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestRouter_WithReporter(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	err := errors.New("failed")
+
+	given.
+		a_reporter().and().
+		a_handler_which_fails_is_registered_for_command("foo", err)
+
+	when.
+		the_router_is_called_for_command_by_user_in_guild("foo", "user", "guild")
+
+	r := then.the_last_report_should_be("interaction", "foo", "user", "guild")
+	then.require.ErrorIs(r.Err, err)
+	then.require.False(r.Panicked())
+	then.require.Equal("interaction", r.InteractionID)
+}
+
+func TestRouter_WithReporter_Panic(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_reporter().and().
+		a_handler_which_panics_is_registered_for_component("foo")
+
+	when.
+		the_router_is_called_for_component("foo")
+
+	r := then.the_last_report_should_be("interaction", "foo", "", "")
+	then.require.True(r.Panicked())
+	then.require.EqualError(r.Err, "panic: oops")
+	then.require.Contains(string(r.Stack), "a_handler_which_panics_is_registered_for_component")
+}
+
+func TestRouter_EntitlementHandler_Panic(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_reporter()
+
+	given.router.RegisterEntitlementHandler(func(_ context.Context, _ *discordgo.Session, _ EntitlementEvent, _ *discordgo.Entitlement) error {
+		panic("oops")
+	})
+
+	when.
+		an_entitlement_is_created_updated_and_deleted(&discordgo.Entitlement{SKUID: "sku", UserID: "user"})
+
+	then.require.Len(then.reports, 3)
+	then.the_last_report_should_be("entitlement", "sku", "user", "")
+}
@@ -0,0 +1,155 @@
+package router
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+// ResolveUser returns the user selected by the user or mentionable option, or false if the option was not provided.
+// Users missing from the interaction's resolved data are looked up in the session's state.
+func ResolveUser(s *discordgo.Session, i *discordgo.InteractionCreate, option string) (*discordgo.User, bool) {
+	id, resolved, ok := resolveOption(i, option)
+	if !ok {
+		return nil, false
+	}
+
+	if u, ok := resolved.Users[id]; ok {
+		return u, true
+	}
+
+	if m, ok := stateMember(s, i.GuildID, id); ok && m.User != nil {
+		return m.User, true
+	}
+
+	return nil, false
+}
+
+// ResolveMember returns the guild member selected by the user or mentionable option, or false if the option was not
+// provided or the command was not invoked in a guild. The resolved member is merged with its user and guild ID, which
+// the interaction's resolved data holds separately. Members missing from the resolved data are looked up in the
+// session's state.
+func ResolveMember(s *discordgo.Session, i *discordgo.InteractionCreate, option string) (*discordgo.Member, bool) {
+	id, resolved, ok := resolveOption(i, option)
+	if !ok {
+		return nil, false
+	}
+
+	return resolveMember(s, i.GuildID, resolved, id)
+}
+
+// resolveMember merges the resolved member with its user and guild ID, and the fields only available from the state
+func resolveMember(s *discordgo.Session, guildID string, resolved *discordgo.ApplicationCommandInteractionDataResolved, id string) (*discordgo.Member, bool) {
+	if guildID == "" {
+		return nil, false
+	}
+
+	resolvedMember, ok := resolved.Members[id]
+	if !ok {
+		return stateMember(s, guildID, id)
+	}
+
+	m := *resolvedMember
+	m.GuildID = guildID
+
+	if u, ok := resolved.Users[id]; ok {
+		m.User = u
+	}
+
+	// the resolved member omits fields such as whether they are deafened or muted, which the state may have
+	if sm, ok := stateMember(s, guildID, id); ok {
+		m.Deaf, m.Mute = sm.Deaf, sm.Mute
+
+		if m.User == nil {
+			m.User = sm.User
+		}
+	}
+
+	return &m, true
+}
+
+// ResolveRole returns the role selected by the role or mentionable option, or false if the option was not provided.
+// Roles missing from the interaction's resolved data are looked up in the session's state.
+func ResolveRole(s *discordgo.Session, i *discordgo.InteractionCreate, option string) (*discordgo.Role, bool) {
+	id, resolved, ok := resolveOption(i, option)
+	if !ok {
+		return nil, false
+	}
+
+	if r, ok := resolved.Roles[id]; ok {
+		return r, true
+	}
+
+	if s == nil || s.State == nil || i.GuildID == "" {
+		return nil, false
+	}
+
+	r, err := s.State.Role(i.GuildID, id)
+
+	return r, err == nil
+}
+
+// ResolveChannel returns the channel selected by the channel option, or false if the option was not provided. The
+// interaction's resolved data only holds partial channels, so the channel is taken from the session's state if present.
+func ResolveChannel(s *discordgo.Session, i *discordgo.InteractionCreate, option string) (*discordgo.Channel, bool) {
+	id, resolved, ok := resolveOption(i, option)
+	if !ok {
+		return nil, false
+	}
+
+	if s != nil && s.State != nil {
+		if c, err := s.State.Channel(id); err == nil {
+			return c, true
+		}
+	}
+
+	c, ok := resolved.Channels[id]
+
+	return c, ok
+}
+
+// ResolveAttachment returns the file uploaded to the attachment option, or false if the option was not provided.
+func ResolveAttachment(i *discordgo.InteractionCreate, option string) (*discordgo.MessageAttachment, bool) {
+	id, resolved, ok := resolveOption(i, option)
+	if !ok {
+		return nil, false
+	}
+
+	a, ok := resolved.Attachments[id]
+
+	return a, ok
+}
+
+// resolveOption returns the ID held by the option, and the interaction's resolved data
+func resolveOption(i *discordgo.InteractionCreate, option string) (string, *discordgo.ApplicationCommandInteractionDataResolved, bool) {
+	if i.Type != discordgo.InteractionApplicationCommand && i.Type != discordgo.InteractionApplicationCommandAutocomplete {
+		return "", nil, false
+	}
+
+	data := i.ApplicationCommandData()
+
+	o, ok := OptionsOf(data)[option]
+	if !ok {
+		return "", nil, false
+	}
+
+	id, ok := o.Value.(string)
+	if !ok {
+		return "", nil, false
+	}
+
+	resolved := data.Resolved
+	if resolved == nil {
+		resolved = &discordgo.ApplicationCommandInteractionDataResolved{}
+	}
+
+	return id, resolved, true
+}
+
+func stateMember(s *discordgo.Session, guildID, userID string) (*discordgo.Member, bool) {
+	if s == nil || s.State == nil || guildID == "" {
+		return nil, false
+	}
+
+	m, err := s.State.Member(guildID, userID)
+
+	return m, err == nil
+}
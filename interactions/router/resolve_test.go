@@ -0,0 +1,113 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+)
+
+func resolveStage(t *testing.T) (*discordgo.Session, *discordgo.InteractionCreate) {
+	s, _ := discordgo.New("Bot token")
+
+	require.NoError(t, s.State.GuildAdd(&discordgo.Guild{ID: "guild"}))
+	require.NoError(t, s.State.MemberAdd(&discordgo.Member{GuildID: "guild", User: &discordgo.User{ID: "cached", Username: "cached"}, Deaf: true}))
+	require.NoError(t, s.State.MemberAdd(&discordgo.Member{GuildID: "guild", User: &discordgo.User{ID: "user", Username: "user"}, Mute: true}))
+	require.NoError(t, s.State.RoleAdd("guild", &discordgo.Role{ID: "cached-role", Name: "cached"}))
+	require.NoError(t, s.State.ChannelAdd(&discordgo.Channel{ID: "channel", GuildID: "guild", Name: "general", Topic: "chat"}))
+
+	i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type:    discordgo.InteractionApplicationCommand,
+		GuildID: "guild",
+		Data: discordgo.ApplicationCommandInteractionData{
+			Name: "foo",
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "user", Type: discordgo.ApplicationCommandOptionUser, Value: "user"},
+				{Name: "cached", Type: discordgo.ApplicationCommandOptionUser, Value: "cached"},
+				{Name: "role", Type: discordgo.ApplicationCommandOptionRole, Value: "role"},
+				{Name: "cached-role", Type: discordgo.ApplicationCommandOptionRole, Value: "cached-role"},
+				{Name: "channel", Type: discordgo.ApplicationCommandOptionChannel, Value: "channel"},
+				{Name: "thread", Type: discordgo.ApplicationCommandOptionChannel, Value: "thread"},
+				{Name: "file", Type: discordgo.ApplicationCommandOptionAttachment, Value: "file"},
+			},
+			Resolved: &discordgo.ApplicationCommandInteractionDataResolved{
+				Users:       map[string]*discordgo.User{"user": {ID: "user", Username: "resolved"}},
+				Members:     map[string]*discordgo.Member{"user": {Nick: "nick", Permissions: discordgo.PermissionAdministrator}},
+				Roles:       map[string]*discordgo.Role{"role": {ID: "role", Name: "resolved"}},
+				Channels:    map[string]*discordgo.Channel{"channel": {ID: "channel", Name: "general"}, "thread": {ID: "thread", Name: "thread"}},
+				Attachments: map[string]*discordgo.MessageAttachment{"file": {ID: "file", Filename: "file.txt"}},
+			},
+		},
+	}}
+
+	return s, i
+}
+
+func TestResolveUser(t *testing.T) {
+	s, i := resolveStage(t)
+
+	u, ok := ResolveUser(s, i, "user")
+	require.True(t, ok)
+	require.Equal(t, "resolved", u.Username)
+
+	u, ok = ResolveUser(s, i, "cached")
+	require.True(t, ok)
+	require.Equal(t, "cached", u.Username)
+
+	_, ok = ResolveUser(s, i, "missing")
+	require.False(t, ok)
+}
+
+func TestResolveMember(t *testing.T) {
+	s, i := resolveStage(t)
+
+	m, ok := ResolveMember(s, i, "user")
+	require.True(t, ok)
+	require.Equal(t, "nick", m.Nick)
+	require.Equal(t, "guild", m.GuildID)
+	require.Equal(t, "resolved", m.User.Username)
+	require.True(t, m.Mute, "fields missing from the resolved member should be taken from the state")
+
+	m, ok = ResolveMember(s, i, "cached")
+	require.True(t, ok)
+	require.True(t, m.Deaf)
+
+	i.GuildID = ""
+	_, ok = ResolveMember(s, i, "user")
+	require.False(t, ok)
+}
+
+func TestResolveRole(t *testing.T) {
+	s, i := resolveStage(t)
+
+	r, ok := ResolveRole(s, i, "role")
+	require.True(t, ok)
+	require.Equal(t, "resolved", r.Name)
+
+	r, ok = ResolveRole(s, i, "cached-role")
+	require.True(t, ok)
+	require.Equal(t, "cached", r.Name)
+}
+
+func TestResolveChannel(t *testing.T) {
+	s, i := resolveStage(t)
+
+	c, ok := ResolveChannel(s, i, "channel")
+	require.True(t, ok)
+	require.Equal(t, "chat", c.Topic)
+
+	c, ok = ResolveChannel(s, i, "thread")
+	require.True(t, ok)
+	require.Equal(t, "thread", c.Name)
+}
+
+func TestResolveAttachment(t *testing.T) {
+	_, i := resolveStage(t)
+
+	a, ok := ResolveAttachment(i, "file")
+	require.True(t, ok)
+	require.Equal(t, "file.txt", a.Filename)
+
+	_, ok = ResolveAttachment(i, "user")
+	require.False(t, ok)
+}
@@ -0,0 +1,159 @@
+package router
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Reasons the router rejects application command invocations, see Responder.Reject.
+const (
+	RejectedContext     = "context"
+	RejectedEntitlement = "entitlement"
+	RejectedValidation  = "validation"
+)
+
+// Responder responds to an interaction, tracking whether it has already been acknowledged so that the initial response,
+// a deferred response and follow-up messages are sent correctly.
+type Responder struct {
+	router      *Router
+	session     *discordgo.Session
+	interaction *discordgo.InteractionCreate
+
+	mu       sync.Mutex
+	deferred bool
+	// responded is true once a response has been sent, at which point further responses are sent as follow-ups
+	responded bool
+	// followupID is the ID of the last follow-up message sent, if any
+	followupID string
+	// rejection is the reason the interaction was rejected, if it was
+	rejection string
+}
+
+// Responder returns a Responder for the interaction. Handlers called by the router can use ResponderFromContext to
+// retrieve the responder for the interaction being handled.
+func (r *Router) Responder(s *discordgo.Session, i *discordgo.InteractionCreate) *Responder {
+	return &Responder{
+		router:      r,
+		session:     s,
+		interaction: i,
+		deferred:    r.acknowledged(i),
+	}
+}
+
+type responderKey struct{}
+
+// ResponderFromContext returns the responder for the interaction being handled, or nil if ctx was not created by a
+// Router.
+func ResponderFromContext(ctx context.Context) *Responder {
+	rs, _ := ctx.Value(responderKey{}).(*Responder)
+
+	return rs
+}
+
+func withResponder(ctx context.Context, rs *Responder) context.Context {
+	return context.WithValue(ctx, responderKey{}, rs)
+}
+
+// Session returns the session used to respond.
+func (rs *Responder) Session() *discordgo.Session {
+	return rs.session
+}
+
+// Interaction returns the interaction being responded to.
+func (rs *Responder) Interaction() *discordgo.InteractionCreate {
+	return rs.interaction
+}
+
+// Reject responds to the interaction, recording that it was rejected for the reason instead of being handled, e.g.
+// because the user is not entitled to the command. Middleware can check for rejections with Rejection once the handler
+// has returned.
+func (rs *Responder) Reject(ctx context.Context, reason string, data *discordgo.InteractionResponseData) error {
+	rs.mu.Lock()
+	rs.rejection = reason
+	rs.mu.Unlock()
+
+	return rs.Respond(ctx, data)
+}
+
+// Rejection returns the reason the interaction was rejected with Reject, or "" if it was not rejected.
+func (rs *Responder) Rejection() string {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	return rs.rejection
+}
+
+// Respond sends a message in response to the interaction. The first response is sent as the interaction's initial
+// response, or fills in the deferred response if one was sent. Subsequent responses are sent as follow-up messages.
+func (rs *Responder) Respond(ctx context.Context, data *discordgo.InteractionResponseData) error {
+	_, err := rs.respond(ctx, data)
+
+	return err
+}
+
+// originalMessageID identifies the original response to an interaction in place of a follow-up message's ID
+const originalMessageID = "@original"
+
+// respond sends the response, returning the ID of the follow-up message sent, or "@original" for the original response
+func (rs *Responder) respond(ctx context.Context, data *discordgo.InteractionResponseData) (string, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	messageID := originalMessageID
+
+	var err error
+	switch {
+	case rs.responded:
+		var m *discordgo.Message
+		m, err = rs.session.FollowupMessageCreate(rs.interaction.Interaction, true, &discordgo.WebhookParams{
+			Content:    data.Content,
+			Embeds:     data.Embeds,
+			Components: data.Components,
+			Flags:      data.Flags,
+		}, discordgo.WithContext(ctx))
+		if err == nil {
+			rs.followupID = m.ID
+			messageID = m.ID
+		}
+	case rs.deferred:
+		_, err = rs.session.InteractionResponseEdit(rs.interaction.Interaction, webhookEdit(data), discordgo.WithContext(ctx))
+	default:
+		err = rs.session.InteractionRespond(rs.interaction.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: data,
+		}, discordgo.WithContext(ctx))
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	rs.responded = true
+
+	return messageID, nil
+}
+
+// Edit edits the message most recently sent by Respond.
+func (rs *Responder) Edit(ctx context.Context, data *discordgo.InteractionResponseData) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	var err error
+	if rs.followupID != "" {
+		_, err = rs.session.FollowupMessageEdit(rs.interaction.Interaction, rs.followupID, webhookEdit(data), discordgo.WithContext(ctx))
+	} else {
+		_, err = rs.session.InteractionResponseEdit(rs.interaction.Interaction, webhookEdit(data), discordgo.WithContext(ctx))
+	}
+
+	return err
+}
+
+func webhookEdit(data *discordgo.InteractionResponseData) *discordgo.WebhookEdit {
+	return &discordgo.WebhookEdit{
+		Content:    &data.Content,
+		Embeds:     &data.Embeds,
+		Components: &data.Components,
+	}
+}
@@ -0,0 +1,41 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponder_Respond(t *testing.T) {
+	tests := map[string]struct {
+		deferred       bool
+		method, suffix string
+	}{
+		"initial response":  {method: http.MethodPost, suffix: "/callback"},
+		"deferred response": {deferred: true, method: http.MethodPatch, suffix: "/messages/@original"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, _, _ := NewRouterStage(t)
+			WithDeferredResponse(tt.deferred)(s.router)
+
+			rs := s.router.Responder(s.session, &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+				ID:    "interaction",
+				AppID: "app",
+				Token: "token",
+				Type:  discordgo.InteractionApplicationCommand,
+			}})
+
+			require.NoError(t, rs.Respond(context.Background(), &discordgo.InteractionResponseData{Content: "foo"}))
+			require.NoError(t, rs.Respond(context.Background(), &discordgo.InteractionResponseData{Content: "bar"}))
+
+			require.Len(t, s.transport.requests, 2)
+			require.Len(t, s.transport.requestsTo(tt.method, tt.suffix), 1, "the first response should be sent to %s", tt.suffix)
+			require.Len(t, s.transport.requestsTo(http.MethodPost, "/webhooks/app/token"), 1, "the second response should be a follow-up")
+		})
+	}
+}
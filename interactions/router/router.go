@@ -1,11 +1,17 @@
 package router
 
 import (
+	"cmp"
 	"context"
 	"log/slog"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/i18n"
 	pkglog "github.com/elliotwms/bot/log"
+	"github.com/elliotwms/bot/report"
 )
 
 type ApplicationCommandHandler func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) (err error)
@@ -15,17 +21,37 @@ type key struct {
 	commandType discordgo.ApplicationCommandType
 }
 
+// Router routes interactions to the registered handlers. Routes may be registered and unregistered while the router
+// is handling interactions.
 type Router struct {
-	applicationCommandHandlers map[key]ApplicationCommandHandler
+	mu                         sync.RWMutex
+	applicationCommandHandlers map[key]commandRoute
+	componentHandlers          map[string]handlerRoute[ComponentHandler]
+	modalHandlers              map[string]handlerRoute[ModalSubmitHandler]
+	autocompleteHandlers       map[string]handlerRoute[AutocompleteHandler]
+	entitlementHandlers        []EntitlementHandler
+	middleware                 []Middleware
+	reporter                   report.Reporter
+	shadowObserver             func(ctx context.Context, r ShadowResult)
 	log                        *slog.Logger
 	deferredResponseEnabled    bool
+	catalog                    *i18n.Catalog
+	seen                       SeenStore
+	collectors                 map[string][]*collector
+	closed                     chan struct{}
+	closeOnce                  sync.Once
 }
 
 type Option func(*Router)
 
 func New(options ...func(*Router)) *Router {
 	r := &Router{
-		applicationCommandHandlers: make(map[key]ApplicationCommandHandler),
+		applicationCommandHandlers: make(map[key]commandRoute),
+		componentHandlers:          make(map[string]handlerRoute[ComponentHandler]),
+		modalHandlers:              make(map[string]handlerRoute[ModalSubmitHandler]),
+		autocompleteHandlers:       make(map[string]handlerRoute[AutocompleteHandler]),
+		collectors:                 make(map[string][]*collector),
+		closed:                     make(chan struct{}),
 		log:                        slog.New(pkglog.DiscardHandler),
 	}
 
@@ -49,25 +75,151 @@ func WithDeferredResponse(enabled bool) Option {
 	}
 }
 
-func (r *Router) RegisterCommand(name string, commandType discordgo.ApplicationCommandType, handler ApplicationCommandHandler) {
-	r.applicationCommandHandlers[key{name: name, commandType: commandType}] = handler
+// WithCatalog makes a translator for the interaction's locale, falling back to the guild's locale, available to
+// handlers via i18n.FromContext.
+func WithCatalog(c *i18n.Catalog) Option {
+	return func(r *Router) {
+		r.catalog = c
+	}
+}
+
+// RegisterCommand registers a handler for the application command. Route options restrict how the command can be
+// invoked.
+func (r *Router) RegisterCommand(name string, commandType discordgo.ApplicationCommandType, handler ApplicationCommandHandler, opts ...RouteOption) {
+	route := commandRoute{handler: handler}
+
+	for _, o := range opts {
+		o(&route)
+	}
+
+	if err := route.validateVariants(); err != nil {
+		r.log.Error("Ignoring invalid variants", slog.String("command", name), "error", err)
+		route.variants = nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.applicationCommandHandlers[key{name: name, commandType: commandType}] = route
+}
+
+// UnregisterCommand removes the handler for the application command, if registered.
+func (r *Router) UnregisterCommand(name string, commandType discordgo.ApplicationCommandType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.applicationCommandHandlers, key{name: name, commandType: commandType})
+}
+
+// Route describes a route registered with the router.
+type Route struct {
+	// Type is the type of interaction handled by the route
+	Type discordgo.InteractionType
+	// Name is the name of the command, or the custom ID of the component or modal, handled by the route
+	Name string
+	// CommandType is the type of application command handled by the route
+	CommandType discordgo.ApplicationCommandType
+	// Deferred is true when the router sends a deferred response before calling the route's handler
+	Deferred bool
+	// Contexts are the contexts the command can be invoked in, or nil if it can be invoked in any context
+	Contexts []discordgo.InteractionContextType
+	// SKUs are the SKUs which entitle users to invoke the command, or nil if it is free
+	SKUs []string
+	// Variants are the names of the command's variants, including shadow variants, or nil if it has none
+	Variants []string
+	// Middleware is the number of middleware the route's handler is wrapped in, including the router's middleware
+	Middleware int
+}
+
+// Routes returns a description of every route registered with the router, ordered by type and name.
+func (r *Router) Routes() []Route {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	routes := make([]Route, 0, len(r.applicationCommandHandlers)+len(r.componentHandlers)+len(r.modalHandlers)+len(r.autocompleteHandlers))
+
+	for k, route := range r.applicationCommandHandlers {
+		routes = append(routes, Route{
+			Type:        discordgo.InteractionApplicationCommand,
+			Name:        k.name,
+			CommandType: k.commandType,
+			Deferred:    r.deferredResponseEnabled,
+			Contexts:    route.contexts,
+			SKUs:        route.skus,
+			Variants:    route.variantNames(),
+			Middleware:  len(r.middleware) + len(route.middleware),
+		})
+	}
+
+	for name, route := range r.autocompleteHandlers {
+		routes = append(routes, Route{
+			Type:        discordgo.InteractionApplicationCommandAutocomplete,
+			Name:        name,
+			CommandType: discordgo.ChatApplicationCommand,
+			Middleware:  len(r.middleware) + len(route.middleware),
+		})
+	}
+
+	for customID, route := range r.componentHandlers {
+		routes = append(routes, Route{
+			Type:       discordgo.InteractionMessageComponent,
+			Name:       customID,
+			Middleware: len(r.middleware) + len(route.middleware),
+		})
+	}
+
+	for customID, route := range r.modalHandlers {
+		routes = append(routes, Route{
+			Type:       discordgo.InteractionModalSubmit,
+			Name:       customID,
+			Middleware: len(r.middleware) + len(route.middleware),
+		})
+	}
+
+	slices.SortFunc(routes, func(a, b Route) int {
+		return cmp.Or(
+			cmp.Compare(a.Type, b.Type),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.CommandType, b.CommandType),
+		)
+	})
+
+	return routes
 }
 
 // Handle implements the discordgo.InteractionCreate handler, dispatching events to the relevant handlers within the
-// router. Currently only application commands are supported
+// router
 func (r *Router) Handle(s *discordgo.Session, e *discordgo.InteractionCreate) {
 	_ = r.HandleWithContext(context.Background(), s, e)
 }
 
 // HandleWithContext propagates the context and provides a request/response pattern for interaction handling (e.g. via Lambda)
 func (r *Router) HandleWithContext(ctx context.Context, is *discordgo.Session, i *discordgo.InteractionCreate) *discordgo.InteractionResponse {
-	// todo support other interaction types i.e. message component, autocomplete, modal submit
+	if r.seen != nil && i.Type != discordgo.InteractionPing && r.isDuplicate(ctx, i) {
+		return nil
+	}
+
+	ctx = withResponder(ctx, r.Responder(is, i))
+
+	if r.catalog != nil {
+		ctx = i18n.NewContext(ctx, r.translator(i.Interaction))
+	}
+
 	switch i.Type {
 	case discordgo.InteractionPing:
 		return &discordgo.InteractionResponse{Type: discordgo.InteractionResponsePong}
 	case discordgo.InteractionApplicationCommand:
 		r.handleApplicationCommand(ctx, is, i)
 		return nil
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		r.handleAutocomplete(ctx, is, i)
+		return nil
+	case discordgo.InteractionMessageComponent:
+		r.handleComponent(ctx, is, i)
+		return nil
+	case discordgo.InteractionModalSubmit:
+		r.handleModalSubmit(ctx, is, i)
+		return nil
 	default:
 		return &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -99,13 +251,87 @@ func (r *Router) handleApplicationCommand(ctx context.Context, s *discordgo.Sess
 		}
 	}
 
-	h, ok := r.applicationCommandHandlers[key{command.Name, command.CommandType}]
+	r.mu.RLock()
+	route, ok := r.applicationCommandHandlers[key{command.Name, command.CommandType}]
+	r.mu.RUnlock()
 	if !ok {
 		log.Error("Handler not found for application command", "name", command.Name)
 		return
 	}
 
-	if err := h(ctx, s, e, command); err != nil {
-		log.Error("Failed to handle interaction", "error", err)
+	variant, shadows := route.choose(command.Name, e.Interaction)
+	if len(route.variants) > 0 {
+		log = log.With(slog.String("variant", variant.Name))
+		ctx = context.WithValue(ctx, variantKey{}, variant.Name)
+	}
+
+	err := r.run(ctx, s, e, route.middleware, func(ctx context.Context, s *discordgo.Session, e *discordgo.InteractionCreate) error {
+		return r.handleCommandRoute(ctx, s, e, route, variant, shadows, command, log)
+	})
+	if err != nil {
+		r.fail(ctx, log, e, command.Name, err)
 	}
 }
+
+// handleCommandRoute checks that the command can be invoked before calling its handler, responding with an ephemeral
+// error otherwise
+func (r *Router) handleCommandRoute(ctx context.Context, s *discordgo.Session, e *discordgo.InteractionCreate, route commandRoute, variant Variant, shadows []Variant, command discordgo.ApplicationCommandInteractionData, log *slog.Logger) error {
+	if !route.allows(e.Context) {
+		log.Warn("Application command invoked in unsupported context", slog.Any("context", e.Context))
+
+		return ResponderFromContext(ctx).Reject(ctx, RejectedContext, &discordgo.InteractionResponseData{
+			Content: contextError(route.contexts),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+	}
+
+	if !route.entitled(e.Interaction) {
+		log.Info("Application command invoked without entitlement")
+
+		return ResponderFromContext(ctx).Reject(ctx, RejectedEntitlement, premiumRequired(route.skus[0]))
+	}
+
+	if violations := route.validate(command); len(violations) > 0 {
+		log.Debug("Application command invoked with invalid options", slog.Int("violations", len(violations)))
+
+		return ResponderFromContext(ctx).Reject(ctx, RejectedValidation, invalidOptions(ctx, violations))
+	}
+
+	start := time.Now()
+	err := variant.Handler(ctx, s, e, command)
+
+	r.shadow(ctx, s, e, command, log, shadows, ShadowResult{
+		InteractionID: e.ID,
+		Route:         command.Name,
+		Variant:       variant.Name,
+		Err:           err,
+		Duration:      time.Since(start),
+	})
+
+	return err
+}
+
+func (r *Router) translator(i *discordgo.Interaction) *i18n.Translator {
+	locales := []discordgo.Locale{i.Locale}
+	if i.GuildLocale != nil {
+		locales = append(locales, *i.GuildLocale)
+	}
+
+	return r.catalog.Translator(locales...)
+}
+
+// isDuplicate reports whether the interaction has already been handled. If the store fails the interaction is handled,
+// as dropping it would leave it unacknowledged.
+func (r *Router) isDuplicate(ctx context.Context, i *discordgo.InteractionCreate) bool {
+	seen, err := r.seen.MarkSeen(ctx, i.ID)
+	if err != nil {
+		r.log.Error("Failed to check for duplicate interaction", slog.String("interaction", i.ID), "error", err)
+		return false
+	}
+
+	if seen {
+		r.log.Warn("Dropping duplicate interaction", slog.String("interaction", i.ID))
+	}
+
+	return seen
+}
@@ -1,11 +1,22 @@
 package router
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/i18n"
+	"github.com/elliotwms/bot/report"
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,30 +24,74 @@ type RouterStage struct {
 	t       testing.TB
 	require *require.Assertions
 
+	session       *discordgo.Session
+	transport     *recordingTransport
 	router        *Router
-	handlerCalled int
+	handlerCalled atomic.Int64
+	handledByMu   sync.Mutex
+	handledBy     []string
+	routes        []Route
+	translations  []string
+	paginator     *Paginator
+	confirmed     chan confirmResult
+	collected     <-chan *discordgo.InteractionCreate
+	prompted      chan error
+	entitlements  []EntitlementEvent
+	target        string
+	middleware    []string
+	sub           *Router
+	err           error
+	reports       []report.Report
+	variantsMu    sync.Mutex
+	variants      []string
+	shadowed      chan ShadowResult
+}
+
+type confirmResult struct {
+	confirmed bool
+	err       error
 }
 
 func NewRouterStage(t *testing.T) (*RouterStage, *RouterStage, *RouterStage) {
 	s := &RouterStage{
-		t:       t,
-		require: require.New(t),
-		router:  New(WithLogger(slog.Default())),
+		t:         t,
+		require:   require.New(t),
+		router:    New(WithLogger(slog.Default())),
+		transport: &recordingTransport{},
 	}
 
+	s.session, _ = discordgo.New("Bot token")
+	s.session.Client = &http.Client{Transport: s.transport}
+
 	return s, s, s
 }
 
-func (s *RouterStage) a_handler_is_registered_for_command(name string) {
-	s.router.RegisterCommand(name, discordgo.ChatApplicationCommand, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) (err error) {
-		s.handlerCalled++
+func (s *RouterStage) and() *RouterStage {
+	return s
+}
+
+func (s *RouterStage) deferred_responses_are_enabled() *RouterStage {
+	WithDeferredResponse(true)(s.router)
+
+	return s
+}
+
+func (s *RouterStage) a_handler_is_registered_for_command(name string) *RouterStage {
+	return s.a_handler_is_registered_for_command_of_type(name, discordgo.ChatApplicationCommand)
+}
+
+func (s *RouterStage) a_handler_is_registered_for_command_of_type(name string, t discordgo.ApplicationCommandType) *RouterStage {
+	s.router.RegisterCommand(name, t, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) (err error) {
+		s.handlerCalled.Add(1)
 
 		return nil
 	})
+
+	return s
 }
 
 func (s *RouterStage) the_router_is_called_for_command(name string) {
-	s.router.Handle(nil, &discordgo.InteractionCreate{
+	s.router.Handle(s.session, &discordgo.InteractionCreate{
 		Interaction: &discordgo.Interaction{
 			Type: discordgo.InteractionApplicationCommand,
 			Data: discordgo.ApplicationCommandInteractionData{
@@ -48,5 +103,1041 @@ func (s *RouterStage) the_router_is_called_for_command(name string) {
 }
 
 func (s *RouterStage) the_handler_should_have_been_called_n_times(i int) {
-	s.require.Equal(i, s.handlerCalled)
+	s.require.EqualValues(i, s.handlerCalled.Load())
+}
+
+func (s *RouterStage) the_routes_are_listed() {
+	s.routes = s.router.Routes()
+}
+
+func (s *RouterStage) the_routes_should_be(routes ...Route) {
+	s.require.Equal(routes, s.routes)
+}
+
+func (s *RouterStage) the_handler_for_command_is_unregistered(name string) *RouterStage {
+	s.router.UnregisterCommand(name, discordgo.ChatApplicationCommand)
+
+	return s
+}
+
+func (s *RouterStage) a_handler_is_registered_for_component(customID string) *RouterStage {
+	s.router.RegisterComponent(customID, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.MessageComponentInteractionData) (err error) {
+		s.handlerCalled.Add(1)
+		s.handledByMu.Lock()
+		s.handledBy = append(s.handledBy, customID)
+		s.handledByMu.Unlock()
+
+		return nil
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_handler_for_component_is_unregistered(customID string) *RouterStage {
+	s.router.UnregisterComponent(customID)
+
+	return s
+}
+
+func (s *RouterStage) the_router_is_called_for_component(customID string) *RouterStage {
+	s.router.Handle(s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			Type: discordgo.InteractionMessageComponent,
+			Data: discordgo.MessageComponentInteractionData{
+				CustomID:      customID,
+				ComponentType: discordgo.ButtonComponent,
+			},
+		},
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_component_should_have_been_handled_by(customID string) {
+	s.handledByMu.Lock()
+	defer s.handledByMu.Unlock()
+
+	s.require.Equal([]string{customID}, s.handledBy)
+}
+
+// routes_are_registered_and_called_concurrently registers, calls and unregisters n commands and components, and
+// collects a component from n messages, concurrently, listing the routes while doing so
+func (s *RouterStage) routes_are_registered_and_called_concurrently(n int) {
+	var wg sync.WaitGroup
+	var collected atomic.Int64
+
+	for i := range n {
+		name := fmt.Sprintf("command-%d", i)
+		customID := fmt.Sprintf("component-%d", i)
+		messageID := fmt.Sprintf("message-%d", i)
+
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+
+			s.a_handler_is_registered_for_command(name)
+			s.the_router_is_called_for_command(name)
+			s.router.Routes()
+			s.the_handler_for_command_is_unregistered(name)
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			s.a_handler_is_registered_for_component(customID)
+			s.the_router_is_called_for_component(customID)
+			s.router.Routes()
+			s.the_handler_for_component_is_unregistered(customID)
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			ch := s.router.Collect(ctx, messageID, nil, time.Minute)
+
+			s.the_message_component_is_clicked_by(messageID, customID, "user")
+
+			if _, ok := <-ch; ok {
+				collected.Add(1)
+			}
+
+			cancel()
+
+			// the collector is closed once it has been removed
+			for range ch {
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	s.require.Equal(int64(n), collected.Load(), "every message's component should have been collected")
+}
+
+func (s *RouterStage) the_components_should_have_been_handled_n_times(n int) *RouterStage {
+	s.handledByMu.Lock()
+	defer s.handledByMu.Unlock()
+
+	s.require.Len(s.handledBy, n)
+
+	return s
+}
+
+func (s *RouterStage) a_handler_is_registered_for_modal(customID string) *RouterStage {
+	s.router.RegisterModal(customID, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ModalSubmitInteractionData) (err error) {
+		s.handlerCalled.Add(1)
+		s.handledByMu.Lock()
+		s.handledBy = append(s.handledBy, customID)
+		s.handledByMu.Unlock()
+
+		return nil
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_router_is_called_for_modal(customID string) *RouterStage {
+	s.router.Handle(s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			Type: discordgo.InteractionModalSubmit,
+			Data: discordgo.ModalSubmitInteractionData{
+				CustomID: customID,
+			},
+		},
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_modal_should_have_been_handled_by(customID string) {
+	s.handledByMu.Lock()
+	defer s.handledByMu.Unlock()
+
+	s.require.Equal([]string{customID}, s.handledBy)
+}
+
+func (s *RouterStage) an_autocomplete_handler_is_registered_for_command(name string, choices ...string) *RouterStage {
+	s.router.RegisterAutocomplete(name, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+		s.handlerCalled.Add(1)
+
+		var cs []*discordgo.ApplicationCommandOptionChoice
+		for _, c := range choices {
+			cs = append(cs, &discordgo.ApplicationCommandOptionChoice{Name: c, Value: c})
+		}
+
+		return cs, nil
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_router_is_called_for_autocomplete(name string) *RouterStage {
+	s.router.Handle(s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:    "interaction",
+			Token: "token",
+			Type:  discordgo.InteractionApplicationCommandAutocomplete,
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:        name,
+				CommandType: discordgo.ChatApplicationCommand,
+			},
+		},
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_interaction_should_have_been_responded_to_with(expected *discordgo.InteractionResponse) {
+	res := s.transport.responses()
+	s.require.Len(res, 1)
+
+	s.require.Equal(expected.Type, res[0].Type)
+
+	expectedData, err := json.Marshal(expected.Data)
+	s.require.NoError(err)
+
+	s.require.JSONEq(string(expectedData), string(res[0].Data))
+}
+
+// recordingTransport records the requests made by a session instead of sending them to Discord
+type recordingTransport struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+}
+
+type recordedRequest struct {
+	method string
+	path   string
+	body   []byte
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+	}
+
+	t.mu.Lock()
+	t.requests = append(t.requests, recordedRequest{method: req.Method, path: req.URL.Path, body: body})
+	t.mu.Unlock()
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString("{}")),
+		Request:    req,
+	}, nil
+}
+
+// responses returns the interaction responses sent via the interaction callback endpoint
+func (t *recordingTransport) responses() []recordedResponse {
+	var res []recordedResponse
+	for _, r := range t.requestsTo(http.MethodPost, "/callback") {
+		var rr recordedResponse
+		if err := json.Unmarshal(r.body, &rr); err != nil {
+			continue
+		}
+
+		// InteractionResponseData can't unmarshal components, so read the data as a message instead
+		rr.Message = &discordgo.Message{}
+		if len(rr.Data) > 0 {
+			_ = json.Unmarshal(rr.Data, rr.Message)
+		}
+
+		res = append(res, rr)
+	}
+
+	return res
+}
+
+// requestsTo returns the requests made with the method to paths with the suffix
+func (t *recordingTransport) requestsTo(method, suffix string) []recordedRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var requests []recordedRequest
+	for _, r := range t.requests {
+		if r.method == method && strings.HasSuffix(r.path, suffix) {
+			requests = append(requests, r)
+		}
+	}
+
+	return requests
+}
+
+type recordedResponse struct {
+	Type    discordgo.InteractionResponseType `json:"type"`
+	Data    json.RawMessage                   `json:"data"`
+	Message *discordgo.Message                `json:"-"`
+}
+
+func (s *RouterStage) a_catalog_with_greetings() *RouterStage {
+	c := i18n.New(discordgo.EnglishUS)
+	c.Add(discordgo.EnglishUS, map[string]string{"greeting": "Hello"})
+	c.Add(discordgo.French, map[string]string{"greeting": "Bonjour"})
+	c.Add(discordgo.German, map[string]string{"greeting": "Hallo"})
+
+	WithCatalog(c)(s.router)
+
+	return s
+}
+
+func (s *RouterStage) a_handler_which_translates_the_greeting_is_registered_for_command(name string) *RouterStage {
+	s.router.RegisterCommand(name, discordgo.ChatApplicationCommand, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) (err error) {
+		s.translations = append(s.translations, i18n.FromContext(ctx).T("greeting"))
+
+		return nil
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_router_is_called_for_command_in_locale(name string, locale discordgo.Locale, guildLocale discordgo.Locale) *RouterStage {
+	s.router.Handle(s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			Type:        discordgo.InteractionApplicationCommand,
+			Locale:      locale,
+			GuildLocale: &guildLocale,
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:        name,
+				CommandType: discordgo.ChatApplicationCommand,
+			},
+		},
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_handler_should_have_translated(translations ...string) {
+	s.require.Equal(translations, s.translations)
+}
+
+func (s *RouterStage) deduplication_is_enabled() *RouterStage {
+	WithDeduplication(NewMemoryStore(10, time.Minute))(s.router)
+
+	return s
+}
+
+func (s *RouterStage) the_router_is_called_for_command_with_interaction_id(name, id string) *RouterStage {
+	s.router.Handle(s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:   id,
+			Type: discordgo.InteractionApplicationCommand,
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:        name,
+				CommandType: discordgo.ChatApplicationCommand,
+			},
+		},
+	})
+
+	return s
+}
+
+func (s *RouterStage) a_paginator_with_n_pages(n int, timeout time.Duration) *RouterStage {
+	s.paginator = s.router.NewPaginator("test",
+		func(ctx context.Context, i *discordgo.InteractionCreate) (int, error) {
+			return n, nil
+		},
+		func(ctx context.Context, i *discordgo.InteractionCreate, page int) (*discordgo.InteractionResponseData, error) {
+			return &discordgo.InteractionResponseData{Content: fmt.Sprintf("Page %d", page+1)}, nil
+		},
+		WithPaginatorTimeout(timeout),
+	)
+
+	return s
+}
+
+func (s *RouterStage) the_paginator_responds_to_a_command_from(userID string) *RouterStage {
+	err := s.paginator.Respond(context.Background(), s.session, paginatedCommand(userID))
+	s.require.NoError(err)
+
+	return s
+}
+
+func (s *RouterStage) the_paginator_responds_to_a_command_which_has_been_responded_to(userID string) *RouterStage {
+	i := paginatedCommand(userID)
+	rs := s.router.Responder(s.session, i)
+	ctx := withResponder(context.Background(), rs)
+
+	s.require.NoError(rs.Respond(ctx, &discordgo.InteractionResponseData{Content: "Loading"}))
+	s.require.NoError(s.paginator.Respond(ctx, s.session, i))
+
+	return s
+}
+
+func paginatedCommand(userID string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:     "interaction",
+			Token:  "token",
+			AppID:  "app",
+			Type:   discordgo.InteractionApplicationCommand,
+			Member: &discordgo.Member{User: &discordgo.User{ID: userID}},
+		},
+	}
+}
+
+func (s *RouterStage) the_interaction_should_have_n_responses_and_n_followups(responses, followups int) *RouterStage {
+	s.require.Len(s.transport.responses(), responses)
+	s.require.Len(s.transport.requestsTo(http.MethodPost, "/webhooks/app/token"), followups)
+
+	return s
+}
+
+// the_button_is_clicked_by clicks the button with the label in the most recent response which has it
+func (s *RouterStage) the_button_is_clicked_by(label, userID string) *RouterStage {
+	res := s.transport.responses()
+
+	var customID string
+	for i := len(res) - 1; i >= 0 && customID == ""; i-- {
+		for _, c := range res[i].Message.Components {
+			for _, c := range c.(*discordgo.ActionsRow).Components {
+				if b := c.(*discordgo.Button); b.Label == label {
+					s.require.False(b.Disabled, "the button should be enabled")
+					customID = b.CustomID
+				}
+			}
+		}
+	}
+	s.require.NotEmpty(customID, "the button should exist")
+
+	s.router.Handle(s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:     "click",
+			Token:  "token",
+			Type:   discordgo.InteractionMessageComponent,
+			Member: &discordgo.Member{User: &discordgo.User{ID: userID}},
+			Data: discordgo.MessageComponentInteractionData{
+				CustomID:      customID,
+				ComponentType: discordgo.ButtonComponent,
+			},
+		},
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_last_response_should_be(t discordgo.InteractionResponseType, content string) *RouterStage {
+	res := s.transport.responses()
+	s.require.NotEmpty(res)
+
+	s.require.Equal(t, res[len(res)-1].Type)
+	s.require.Equal(content, res[len(res)-1].Message.Content)
+
+	return s
+}
+
+func (s *RouterStage) the_last_response_should_have_no_components() *RouterStage {
+	res := s.transport.responses()
+	s.require.NotEmpty(res)
+
+	s.require.Empty(res[len(res)-1].Message.Components)
+
+	return s
+}
+
+func (s *RouterStage) the_last_response_should_be_ephemeral() *RouterStage {
+	res := s.transport.responses()
+	s.require.NotEmpty(res)
+
+	s.require.Equal(discordgo.MessageFlagsEphemeral, res[len(res)-1].Message.Flags)
+
+	return s
+}
+
+func (s *RouterStage) the_response_should_be_edited() *RouterStage {
+	s.require.Eventually(func() bool {
+		return len(s.transport.requestsTo(http.MethodPatch, "/messages/@original")) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	return s
+}
+
+func (s *RouterStage) a_handler_which_asks_for_confirmation_is_registered_for_command(name string, timeout time.Duration) *RouterStage {
+	s.confirmed = make(chan confirmResult, 1)
+
+	s.router.RegisterCommand(name, discordgo.ChatApplicationCommand, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) (err error) {
+		confirmed, err := Confirm(ctx, ResponderFromContext(ctx), "Are you sure?", WithConfirmTimeout(timeout))
+		s.confirmed <- confirmResult{confirmed, err}
+
+		return err
+	})
+
+	return s
+}
+
+// the_router_is_called_for_command_by calls the router without waiting for the handler to complete, and waits for
+// the handler to respond
+func (s *RouterStage) the_router_is_called_for_command_by(name, userID string) *RouterStage {
+	go s.router.Handle(s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:     "interaction",
+			Token:  "token",
+			AppID:  "app",
+			Type:   discordgo.InteractionApplicationCommand,
+			Member: &discordgo.Member{User: &discordgo.User{ID: userID}},
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:        name,
+				CommandType: discordgo.ChatApplicationCommand,
+			},
+		},
+	})
+
+	s.require.Eventually(func() bool {
+		return len(s.transport.responses()) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	return s
+}
+
+func (s *RouterStage) the_confirmation_should_return(confirmed bool, err error) *RouterStage {
+	select {
+	case res := <-s.confirmed:
+		s.require.Equal(confirmed, res.confirmed)
+		s.require.ErrorIs(res.err, err)
+	case <-time.After(time.Second):
+		s.require.Fail("the confirmation should have returned")
+	}
+
+	return s
+}
+
+func (s *RouterStage) a_collector_for_message(messageID string, filter CollectFilter, timeout time.Duration) *RouterStage {
+	s.collected = s.router.Collect(context.Background(), messageID, filter, timeout)
+
+	return s
+}
+
+// the_message_component_is_clicked_by clicks a component on the message without waiting for it to be collected
+func (s *RouterStage) the_message_component_is_clicked_by(messageID, customID, userID string) *RouterStage {
+	go s.router.Handle(s.session, messageComponentClick(messageID, customID, userID))
+
+	return s
+}
+
+// the_message_component_is_clicked_by_and_routed clicks a component on the message which should not be collected,
+// waiting for it to be handled by the component routes
+func (s *RouterStage) the_message_component_is_clicked_by_and_routed(messageID, customID, userID string) *RouterStage {
+	s.router.Handle(s.session, messageComponentClick(messageID, customID, userID))
+
+	return s
+}
+
+func messageComponentClick(messageID, customID, userID string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			Type:    discordgo.InteractionMessageComponent,
+			Message: &discordgo.Message{ID: messageID},
+			Member:  &discordgo.Member{User: &discordgo.User{ID: userID}},
+			Data: discordgo.MessageComponentInteractionData{
+				CustomID:      customID,
+				ComponentType: discordgo.ButtonComponent,
+			},
+		},
+	}
+}
+
+func (s *RouterStage) the_router_is_closed() *RouterStage {
+	s.router.Close()
+
+	return s
+}
+
+func (s *RouterStage) the_component_should_be_collected(customID string) *RouterStage {
+	select {
+	case i := <-s.collected:
+		s.require.NotNil(i, "the collector should not be closed")
+		s.require.Equal(customID, i.MessageComponentData().CustomID)
+	case <-time.After(time.Second):
+		s.require.Fail("the component should have been collected")
+	}
+
+	return s
+}
+
+func (s *RouterStage) the_collector_should_be_closed() *RouterStage {
+	select {
+	case i, ok := <-s.collected:
+		s.require.False(ok, "the collector should be closed, but collected %v", i)
+	case <-time.After(time.Second):
+		s.require.Fail("the collector should have been closed")
+	}
+
+	return s
+}
+
+// a_handler_which_prompts_for_a_name_is_registered_for_command greets the user with the name submitted in the modal
+func (s *RouterStage) a_handler_which_prompts_for_a_name_is_registered_for_command(name string, timeout time.Duration) *RouterStage {
+	s.prompted = make(chan error, 1)
+
+	s.router.RegisterCommand(name, discordgo.ChatApplicationCommand, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) (err error) {
+		defer func() { s.prompted <- err }()
+
+		values, rs, err := ResponderFromContext(ctx).Prompt(ctx, &discordgo.InteractionResponseData{
+			Title: "Who are you?",
+			Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.TextInput{CustomID: "name", Label: "Name", Style: discordgo.TextInputShort},
+			}}},
+		}, WithPromptTimeout(timeout))
+		if err != nil {
+			return err
+		}
+
+		return rs.Respond(ctx, &discordgo.InteractionResponseData{Content: "Hello " + values["name"]})
+	})
+
+	return s
+}
+
+// the_modal_is_submitted_with submits the modal in the last response
+func (s *RouterStage) the_modal_is_submitted_with(field, value string) *RouterStage {
+	res := s.transport.responses()
+	s.require.NotEmpty(res)
+	s.require.Equal(discordgo.InteractionResponseModal, res[len(res)-1].Type)
+
+	var modal struct {
+		CustomID string `json:"custom_id"`
+	}
+	s.require.NoError(json.Unmarshal(res[len(res)-1].Data, &modal))
+
+	s.router.Handle(s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:    "submit",
+			Token: "token",
+			Type:  discordgo.InteractionModalSubmit,
+			Data: discordgo.ModalSubmitInteractionData{
+				CustomID: modal.CustomID,
+				Components: []discordgo.MessageComponent{&discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					&discordgo.TextInput{CustomID: field, Value: value},
+				}}},
+			},
+		},
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_prompt_should_return(err error) *RouterStage {
+	select {
+	case res := <-s.prompted:
+		s.require.ErrorIs(res, err)
+	case <-time.After(time.Second):
+		s.require.Fail("the prompt should have returned")
+	}
+
+	return s
+}
+
+func (s *RouterStage) a_handler_is_registered_for_command_in_contexts(name string, contexts ...discordgo.InteractionContextType) *RouterStage {
+	s.router.RegisterCommand(name, discordgo.ChatApplicationCommand, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) (err error) {
+		s.handlerCalled.Add(1)
+
+		return nil
+	}, WithContexts(contexts...))
+
+	return s
+}
+
+func (s *RouterStage) the_router_is_called_for_command_in_context(name string, c discordgo.InteractionContextType) *RouterStage {
+	s.router.Handle(s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:      "interaction",
+			Token:   "token",
+			AppID:   "app",
+			Type:    discordgo.InteractionApplicationCommand,
+			Context: c,
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:        name,
+				CommandType: discordgo.ChatApplicationCommand,
+			},
+		},
+	})
+
+	return s
+}
+
+func (s *RouterStage) a_handler_is_registered_for_command_requiring_sku(name, skuID string) *RouterStage {
+	s.router.RegisterCommand(name, discordgo.ChatApplicationCommand, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) (err error) {
+		s.handlerCalled.Add(1)
+
+		return nil
+	}, RequireSKU(skuID))
+
+	return s
+}
+
+func (s *RouterStage) the_router_is_called_for_command_with_entitlements(name string, entitlements ...*discordgo.Entitlement) *RouterStage {
+	s.router.Handle(s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:           "interaction",
+			Token:        "token",
+			AppID:        "app",
+			Type:         discordgo.InteractionApplicationCommand,
+			Entitlements: entitlements,
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:        name,
+				CommandType: discordgo.ChatApplicationCommand,
+			},
+		},
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_last_response_should_upsell_sku(skuID string) *RouterStage {
+	res := s.transport.responses()
+	s.require.NotEmpty(res)
+
+	last := res[len(res)-1]
+	s.require.Equal(discordgo.MessageFlagsEphemeral, last.Message.Flags)
+	s.require.Len(last.Message.Components, 1)
+
+	button := last.Message.Components[0].(*discordgo.ActionsRow).Components[0].(*discordgo.Button)
+	s.require.Equal(discordgo.PremiumButton, button.Style)
+	s.require.Equal(skuID, button.SKUID)
+
+	return s
+}
+
+func (s *RouterStage) an_entitlement_handler_is_registered() *RouterStage {
+	s.router.RegisterEntitlementHandler(func(ctx context.Context, _ *discordgo.Session, event EntitlementEvent, e *discordgo.Entitlement) error {
+		s.entitlements = append(s.entitlements, event)
+
+		return nil
+	})
+
+	return s
+}
+
+func (s *RouterStage) an_entitlement_is_created_updated_and_deleted(e *discordgo.Entitlement) *RouterStage {
+	s.router.HandleEntitlementCreate(s.session, &discordgo.EntitlementCreate{Entitlement: e})
+	s.router.HandleEntitlementUpdate(s.session, &discordgo.EntitlementUpdate{Entitlement: e})
+	s.router.HandleEntitlementDelete(s.session, &discordgo.EntitlementDelete{Entitlement: e})
+
+	return s
+}
+
+func (s *RouterStage) the_entitlement_events_should_be(events ...EntitlementEvent) *RouterStage {
+	s.require.Equal(events, s.entitlements)
+
+	return s
+}
+
+func (s *RouterStage) a_handler_is_registered_for_command_with_rules(name string, rules ...Rule) *RouterStage {
+	s.router.RegisterCommand(name, discordgo.ChatApplicationCommand, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) (err error) {
+		s.handlerCalled.Add(1)
+
+		return nil
+	}, WithRules(rules...))
+
+	return s
+}
+
+func (s *RouterStage) a_catalog_with_validation_messages() *RouterStage {
+	c := i18n.New(discordgo.EnglishUS)
+	c.Add(discordgo.French, map[string]string{
+		"validation.invalid": "Veuillez corriger les options suivantes :",
+		"validation.greater": "doit être supérieur à {other}",
+	})
+
+	WithCatalog(c)(s.router)
+
+	return s
+}
+
+func (s *RouterStage) the_router_is_called_for_command_in_locale_with_options(name string, locale discordgo.Locale, options ...*discordgo.ApplicationCommandInteractionDataOption) *RouterStage {
+	s.router.Handle(s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:     "interaction",
+			Token:  "token",
+			AppID:  "app",
+			Type:   discordgo.InteractionApplicationCommand,
+			Locale: locale,
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:        name,
+				CommandType: discordgo.ChatApplicationCommand,
+				Options:     options,
+			},
+		},
+	})
+
+	return s
+}
+
+func (s *RouterStage) a_user_command_handler_is_registered(name string) *RouterStage {
+	s.router.RegisterUserCommand(name, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, user *discordgo.User, member *discordgo.Member) error {
+		s.handlerCalled.Add(1)
+		s.target = user.Username
+		if member != nil {
+			s.target += " (" + member.Nick + ")"
+		}
+
+		return nil
+	})
+
+	return s
+}
+
+func (s *RouterStage) a_message_command_handler_is_registered(name string) *RouterStage {
+	s.router.RegisterMessageCommand(name, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, message *discordgo.Message) error {
+		s.handlerCalled.Add(1)
+		s.target = message.Content
+
+		return nil
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_router_is_called_for_context_menu_command(name string, t discordgo.ApplicationCommandType, guildID string, resolved *discordgo.ApplicationCommandInteractionDataResolved) *RouterStage {
+	s.router.Handle(s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			Type:    discordgo.InteractionApplicationCommand,
+			GuildID: guildID,
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:        name,
+				CommandType: t,
+				TargetID:    "target",
+				Resolved:    resolved,
+			},
+		},
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_handler_should_have_received_target(target string) *RouterStage {
+	s.require.Equal(target, s.target)
+
+	return s
+}
+
+// recordMiddleware records the name when it is called
+func (s *RouterStage) recordMiddleware(name string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, ds *discordgo.Session, i *discordgo.InteractionCreate) error {
+			s.middleware = append(s.middleware, name)
+
+			return next(ctx, ds, i)
+		}
+	}
+}
+
+func (s *RouterStage) middleware_is_used(names ...string) *RouterStage {
+	for _, name := range names {
+		s.router.Use(s.recordMiddleware(name))
+	}
+
+	return s
+}
+
+func (s *RouterStage) a_handler_is_registered_for_command_with_middleware(name, middleware string) *RouterStage {
+	s.router.RegisterCommand(name, discordgo.ChatApplicationCommand, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+		s.handlerCalled.Add(1)
+
+		return nil
+	}, WithMiddleware(s.recordMiddleware(middleware)))
+
+	return s
+}
+
+func (s *RouterStage) a_sub_router_with_middleware(name string) *RouterStage {
+	s.sub = New()
+	s.sub.Use(s.recordMiddleware(name))
+
+	return s
+}
+
+func (s *RouterStage) the_sub_router_has_a_command(name string) *RouterStage {
+	s.sub.RegisterCommand(name, discordgo.ChatApplicationCommand, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+		s.handlerCalled.Add(1)
+
+		return nil
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_sub_router_has_a_component(customID string) *RouterStage {
+	s.sub.RegisterComponent(customID, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.MessageComponentInteractionData) error {
+		s.handlerCalled.Add(1)
+
+		return nil
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_sub_router_is_mounted() *RouterStage {
+	s.err = s.router.Mount(s.sub)
+
+	return s
+}
+
+func (s *RouterStage) a_group_with_middleware_and_command(middleware, name string) *RouterStage {
+	s.err = s.router.Group(func(g *Router) {
+		g.Use(s.recordMiddleware(middleware))
+		g.RegisterCommand(name, discordgo.ChatApplicationCommand, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+			s.handlerCalled.Add(1)
+
+			return nil
+		})
+	})
+
+	return s
+}
+
+func (s *RouterStage) no_error_should_be_returned() *RouterStage {
+	s.require.NoError(s.err)
+
+	return s
+}
+
+func (s *RouterStage) the_error_should_be(err error, contains string) *RouterStage {
+	s.require.ErrorIs(s.err, err)
+	s.require.ErrorContains(s.err, contains)
+
+	return s
+}
+
+func (s *RouterStage) the_middleware_should_have_been_called(names ...string) *RouterStage {
+	s.require.Equal(names, s.middleware)
+
+	return s
+}
+
+func (s *RouterStage) a_reporter() *RouterStage {
+	WithReporter(report.ReporterFunc(func(ctx context.Context, r report.Report) {
+		s.reports = append(s.reports, r)
+	}))(s.router)
+
+	return s
+}
+
+func (s *RouterStage) a_handler_which_fails_is_registered_for_command(name string, err error) *RouterStage {
+	s.router.RegisterCommand(name, discordgo.ChatApplicationCommand, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+		return err
+	})
+
+	return s
+}
+
+func (s *RouterStage) a_handler_which_panics_is_registered_for_component(customID string) *RouterStage {
+	s.router.RegisterComponent(customID, func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.MessageComponentInteractionData) error {
+		panic("oops")
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_router_is_called_for_command_by_user_in_guild(name, userID, guildID string) *RouterStage {
+	s.router.Handle(s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:      "interaction",
+			Type:    discordgo.InteractionApplicationCommand,
+			GuildID: guildID,
+			Member:  &discordgo.Member{User: &discordgo.User{ID: userID}},
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:        name,
+				CommandType: discordgo.ChatApplicationCommand,
+			},
+		},
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_last_report_should_be(source, route, userID, guildID string) report.Report {
+	s.require.NotEmpty(s.reports)
+
+	r := s.reports[len(s.reports)-1]
+	s.require.Equal(source, r.Source)
+	s.require.Equal(route, r.Route)
+	s.require.Equal(userID, r.UserID)
+	s.require.Equal(guildID, r.GuildID)
+	s.require.False(r.Time.IsZero())
+
+	return r
+}
+
+// variantHandler records the variant from the context, responding to the interaction
+func (s *RouterStage) variantHandler() ApplicationCommandHandler {
+	return func(ctx context.Context, _ *discordgo.Session, i *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+		s.variantsMu.Lock()
+		s.variants = append(s.variants, VariantFromContext(ctx))
+		s.variantsMu.Unlock()
+
+		return ResponderFromContext(ctx).Respond(ctx, &discordgo.InteractionResponseData{Content: VariantFromContext(ctx)})
+	}
+}
+
+func (s *RouterStage) a_command_with_variants(name string, by Bucketing, variants ...Variant) *RouterStage {
+	for n := range variants {
+		variants[n].Handler = s.variantHandler()
+	}
+
+	s.router.RegisterCommand(name, discordgo.ChatApplicationCommand, s.variantHandler(), WithVariants(by, variants...))
+
+	return s
+}
+
+func (s *RouterStage) a_shadow_observer() *RouterStage {
+	s.shadowed = make(chan ShadowResult, 1)
+
+	WithShadowObserver(func(ctx context.Context, r ShadowResult) {
+		s.shadowed <- r
+	})(s.router)
+
+	return s
+}
+
+func (s *RouterStage) the_router_is_called_for_command_in_guild(name, guildID string) *RouterStage {
+	s.router.Handle(s.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:      "interaction",
+			Token:   "token",
+			AppID:   "app",
+			Type:    discordgo.InteractionApplicationCommand,
+			GuildID: guildID,
+			Member:  &discordgo.Member{User: &discordgo.User{ID: "user"}},
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:        name,
+				CommandType: discordgo.ChatApplicationCommand,
+			},
+		},
+	})
+
+	return s
+}
+
+func (s *RouterStage) the_variants_should_have_handled(names ...string) *RouterStage {
+	s.variantsMu.Lock()
+	defer s.variantsMu.Unlock()
+
+	s.require.Equal(names, s.variants)
+
+	return s
+}
+
+func (s *RouterStage) the_shadow_result_should_be_observed(variant, shadow string) *RouterStage {
+	select {
+	case r := <-s.shadowed:
+		s.require.Equal(variant, r.Variant)
+		s.require.Equal(shadow, r.Shadow)
+		s.require.NoError(r.Err)
+		s.require.NoError(r.ShadowErr)
+	case <-time.After(time.Second):
+		s.t.Fatal("shadow result was not observed")
+	}
+
+	return s
+}
+
+func (s *RouterStage) the_response_content_should_be(content string) *RouterStage {
+	res := s.transport.responses()
+	s.require.Len(res, 1)
+	s.require.Equal(content, res[0].Message.Content)
+
+	return s
 }
@@ -2,6 +2,8 @@ package router
 
 import (
 	"testing"
+
+	"github.com/bwmarrin/discordgo"
 )
 
 func TestRouter_ApplicationCommand(t *testing.T) {
@@ -30,3 +32,169 @@ func TestRouter_ApplicationCommand_NotFound(t *testing.T) {
 	then.
 		the_handler_should_have_been_called_n_times(0)
 }
+
+func TestRouter_Routes(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		deferred_responses_are_enabled().and().
+		middleware_is_used("logging").and().
+		a_handler_is_registered_for_command("foo").and().
+		a_handler_is_registered_for_command_of_type("foo", discordgo.UserApplicationCommand).and().
+		a_handler_is_registered_for_command("bar").and().
+		a_handler_is_registered_for_command_with_middleware("qux", "auth").and().
+		a_handler_is_registered_for_component("baz").and().
+		a_handler_is_registered_for_modal("baz").and().
+		an_autocomplete_handler_is_registered_for_command("bar")
+
+	when.
+		the_routes_are_listed()
+
+	then.
+		the_routes_should_be(
+			Route{Type: discordgo.InteractionApplicationCommand, Name: "bar", CommandType: discordgo.ChatApplicationCommand, Deferred: true, Middleware: 1},
+			Route{Type: discordgo.InteractionApplicationCommand, Name: "foo", CommandType: discordgo.ChatApplicationCommand, Deferred: true, Middleware: 1},
+			Route{Type: discordgo.InteractionApplicationCommand, Name: "foo", CommandType: discordgo.UserApplicationCommand, Deferred: true, Middleware: 1},
+			Route{Type: discordgo.InteractionApplicationCommand, Name: "qux", CommandType: discordgo.ChatApplicationCommand, Deferred: true, Middleware: 2},
+			Route{Type: discordgo.InteractionMessageComponent, Name: "baz", Middleware: 1},
+			Route{Type: discordgo.InteractionApplicationCommandAutocomplete, Name: "bar", CommandType: discordgo.ChatApplicationCommand, Middleware: 1},
+			Route{Type: discordgo.InteractionModalSubmit, Name: "baz", Middleware: 1},
+		)
+}
+
+func TestRouter_UnregisterCommand(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_handler_is_registered_for_command("foo").and().
+		the_handler_for_command_is_unregistered("foo")
+
+	when.
+		the_router_is_called_for_command("foo")
+
+	then.
+		the_handler_should_have_been_called_n_times(0)
+}
+
+func TestRouter_Component(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_handler_is_registered_for_component("foo")
+
+	when.
+		the_router_is_called_for_component("foo")
+
+	then.
+		the_component_should_have_been_handled_by("foo")
+}
+
+func TestRouter_Component_MostSpecificPrefix(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_handler_is_registered_for_component("foo").and().
+		a_handler_is_registered_for_component("foo:bar").and().
+		a_handler_is_registered_for_component("foo:bar:baz:qux")
+
+	when.
+		the_router_is_called_for_component("foo:bar:baz")
+
+	then.
+		the_component_should_have_been_handled_by("foo:bar")
+}
+
+func TestRouter_Component_NotFound(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_handler_is_registered_for_component("foo").and().
+		a_handler_is_registered_for_component("bar").and().
+		the_handler_for_component_is_unregistered("bar")
+
+	when.
+		the_router_is_called_for_component("foobar").and().
+		the_router_is_called_for_component("bar:foo")
+
+	then.
+		the_handler_should_have_been_called_n_times(0)
+}
+
+func TestRouter_Concurrent(t *testing.T) {
+	_, when, then := NewRouterStage(t)
+
+	when.
+		routes_are_registered_and_called_concurrently(100)
+
+	then.
+		the_components_should_have_been_handled_n_times(100).and().
+		the_handler_should_have_been_called_n_times(200)
+}
+
+func TestRouter_Modal(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_handler_is_registered_for_component("foo").and().
+		a_handler_is_registered_for_modal("foo").and().
+		a_handler_is_registered_for_modal("foo:bar")
+
+	when.
+		the_router_is_called_for_modal("foo:baz")
+
+	then.
+		the_modal_should_have_been_handled_by("foo")
+}
+
+func TestRouter_Autocomplete(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		an_autocomplete_handler_is_registered_for_command("foo", "bar", "baz")
+
+	when.
+		the_router_is_called_for_autocomplete("foo")
+
+	then.
+		the_interaction_should_have_been_responded_to_with(&discordgo.InteractionResponse{
+			Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+			Data: &discordgo.InteractionResponseData{
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "bar", Value: "bar"},
+					{Name: "baz", Value: "baz"},
+				},
+			},
+		})
+}
+
+func TestRouter_WithCatalog(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_catalog_with_greetings().and().
+		a_handler_which_translates_the_greeting_is_registered_for_command("foo")
+
+	when.
+		the_router_is_called_for_command_in_locale("foo", discordgo.French, discordgo.German).and().
+		the_router_is_called_for_command_in_locale("foo", discordgo.Japanese, discordgo.German).and().
+		the_router_is_called_for_command_in_locale("foo", discordgo.Japanese, discordgo.Korean)
+
+	then.
+		the_handler_should_have_translated("Bonjour", "Hallo", "Hello")
+}
+
+func TestRouter_WithDeduplication(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		deduplication_is_enabled().and().
+		a_handler_is_registered_for_command("foo")
+
+	when.
+		the_router_is_called_for_command_with_interaction_id("foo", "1").and().
+		the_router_is_called_for_command_with_interaction_id("foo", "1").and().
+		the_router_is_called_for_command_with_interaction_id("foo", "2")
+
+	then.
+		the_handler_should_have_been_called_n_times(2)
+}
@@ -0,0 +1,297 @@
+package routertest
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Default IDs of fixtures.
+const (
+	AppID     = "app"
+	GuildID   = "guild"
+	ChannelID = "channel"
+	UserID    = "user"
+	MessageID = "message"
+)
+
+// ids makes each fixture's interaction ID and token unique, so that its responses can be told apart
+var ids atomic.Int64
+
+// Option configures an interaction fixture.
+type Option func(i *discordgo.InteractionCreate)
+
+// fixture returns an interaction triggered by UserID in ChannelID of GuildID, authorized by a guild install
+func fixture(t discordgo.InteractionType, data discordgo.InteractionData, opts []Option) *discordgo.InteractionCreate {
+	n := strconv.FormatInt(ids.Add(1), 10)
+
+	i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:        "interaction-" + n,
+		Token:     "token-" + n,
+		AppID:     AppID,
+		Type:      t,
+		Data:      data,
+		GuildID:   GuildID,
+		ChannelID: ChannelID,
+		Member:    &discordgo.Member{GuildID: GuildID, User: &discordgo.User{ID: UserID, Username: UserID}},
+		Locale:    discordgo.EnglishUS,
+		Context:   discordgo.InteractionContextGuild,
+		AuthorizingIntegrationOwners: map[discordgo.ApplicationIntegrationType]string{
+			discordgo.ApplicationIntegrationGuildInstall: GuildID,
+		},
+	}}
+
+	for _, o := range opts {
+		o(i)
+	}
+
+	return i
+}
+
+// Command returns an interaction invoking the chat input command. Use Options to set its options.
+func Command(name string, opts ...Option) *discordgo.InteractionCreate {
+	return fixture(discordgo.InteractionApplicationCommand, discordgo.ApplicationCommandInteractionData{
+		ID:          name,
+		Name:        name,
+		CommandType: discordgo.ChatApplicationCommand,
+	}, opts)
+}
+
+// UserCommand returns an interaction invoking the user context menu command on the target.
+func UserCommand(name string, target *discordgo.User, opts ...Option) *discordgo.InteractionCreate {
+	return fixture(discordgo.InteractionApplicationCommand, discordgo.ApplicationCommandInteractionData{
+		ID:          name,
+		Name:        name,
+		CommandType: discordgo.UserApplicationCommand,
+		TargetID:    target.ID,
+		Resolved: &discordgo.ApplicationCommandInteractionDataResolved{
+			Users: map[string]*discordgo.User{target.ID: target},
+		},
+	}, opts)
+}
+
+// MessageCommand returns an interaction invoking the message context menu command on the target.
+func MessageCommand(name string, target *discordgo.Message, opts ...Option) *discordgo.InteractionCreate {
+	return fixture(discordgo.InteractionApplicationCommand, discordgo.ApplicationCommandInteractionData{
+		ID:          name,
+		Name:        name,
+		CommandType: discordgo.MessageApplicationCommand,
+		TargetID:    target.ID,
+		Resolved: &discordgo.ApplicationCommandInteractionDataResolved{
+			Messages: map[string]*discordgo.Message{target.ID: target},
+		},
+	}, opts)
+}
+
+// Autocomplete returns an interaction requesting autocomplete choices for the chat input command. Use Options to set
+// its options, including the Focused one.
+func Autocomplete(name string, opts ...Option) *discordgo.InteractionCreate {
+	return fixture(discordgo.InteractionApplicationCommandAutocomplete, discordgo.ApplicationCommandInteractionData{
+		ID:          name,
+		Name:        name,
+		CommandType: discordgo.ChatApplicationCommand,
+	}, opts)
+}
+
+// Button returns an interaction clicking the button with the custom ID on MessageID.
+func Button(customID string, opts ...Option) *discordgo.InteractionCreate {
+	return component(discordgo.MessageComponentInteractionData{
+		CustomID:      customID,
+		ComponentType: discordgo.ButtonComponent,
+	}, opts)
+}
+
+// Select returns an interaction selecting the values in the string select menu with the custom ID on MessageID.
+func Select(customID string, values []string, opts ...Option) *discordgo.InteractionCreate {
+	return component(discordgo.MessageComponentInteractionData{
+		CustomID:      customID,
+		ComponentType: discordgo.SelectMenuComponent,
+		Values:        values,
+	}, opts)
+}
+
+func component(data discordgo.MessageComponentInteractionData, opts []Option) *discordgo.InteractionCreate {
+	return fixture(discordgo.InteractionMessageComponent, data, append([]Option{
+		WithMessage(&discordgo.Message{ID: MessageID, ChannelID: ChannelID}),
+	}, opts...))
+}
+
+// Modal returns an interaction submitting the modal with the custom ID, with text inputs with the values by custom ID.
+func Modal(customID string, values map[string]string, opts ...Option) *discordgo.InteractionCreate {
+	var rows []discordgo.MessageComponent
+	for _, id := range slices.Sorted(maps.Keys(values)) {
+		rows = append(rows, &discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			&discordgo.TextInput{CustomID: id, Value: values[id]},
+		}})
+	}
+
+	return fixture(discordgo.InteractionModalSubmit, discordgo.ModalSubmitInteractionData{
+		CustomID:   customID,
+		Components: rows,
+	}, opts)
+}
+
+// Options sets the options of a command or autocomplete interaction.
+func Options(options ...*discordgo.ApplicationCommandInteractionDataOption) Option {
+	return func(i *discordgo.InteractionCreate) {
+		data := i.ApplicationCommandData()
+		data.Options = options
+		i.Data = data
+	}
+}
+
+// Resolved sets the resolved data of a command or autocomplete interaction, e.g. the users selected by user options.
+func Resolved(resolved *discordgo.ApplicationCommandInteractionDataResolved) Option {
+	return func(i *discordgo.InteractionCreate) {
+		data := i.ApplicationCommandData()
+		data.Resolved = resolved
+		i.Data = data
+	}
+}
+
+// InGuild sets the guild the interaction was triggered in.
+func InGuild(guildID string) Option {
+	return func(i *discordgo.InteractionCreate) {
+		if i.Member == nil {
+			i.Member = &discordgo.Member{User: i.User}
+			i.User = nil
+		}
+
+		i.GuildID = guildID
+		i.Member.GuildID = guildID
+		i.Context = discordgo.InteractionContextGuild
+		i.AuthorizingIntegrationOwners = map[discordgo.ApplicationIntegrationType]string{
+			discordgo.ApplicationIntegrationGuildInstall: guildID,
+		}
+	}
+}
+
+// InDM triggers the interaction in a DM with the bot instead of a guild.
+func InDM() Option {
+	return func(i *discordgo.InteractionCreate) {
+		if i.Member != nil {
+			i.User = i.Member.User
+			i.Member = nil
+		}
+
+		i.GuildID = ""
+		i.Context = discordgo.InteractionContextBotDM
+		i.AuthorizingIntegrationOwners = map[discordgo.ApplicationIntegrationType]string{
+			discordgo.ApplicationIntegrationGuildInstall: "0",
+		}
+	}
+}
+
+// ByUser sets the user who triggered the interaction.
+func ByUser(u *discordgo.User) Option {
+	return func(i *discordgo.InteractionCreate) {
+		if i.Member != nil {
+			i.Member.User = u
+		} else {
+			i.User = u
+		}
+	}
+}
+
+// WithPermissions sets the permissions of the member who triggered the interaction in the channel.
+func WithPermissions(permissions int64) Option {
+	return func(i *discordgo.InteractionCreate) {
+		if i.Member != nil {
+			i.Member.Permissions = permissions
+		}
+	}
+}
+
+// WithLocale sets the locale of the user who triggered the interaction.
+func WithLocale(locale discordgo.Locale) Option {
+	return func(i *discordgo.InteractionCreate) {
+		i.Locale = locale
+	}
+}
+
+// WithEntitlements sets the entitlements of the user or guild who triggered the interaction.
+func WithEntitlements(entitlements ...*discordgo.Entitlement) Option {
+	return func(i *discordgo.InteractionCreate) {
+		i.Entitlements = entitlements
+	}
+}
+
+// WithMessage sets the message the component interaction was triggered on.
+func WithMessage(m *discordgo.Message) Option {
+	return func(i *discordgo.InteractionCreate) {
+		i.Message = m
+	}
+}
+
+// StringOption returns a string option.
+func StringOption(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+	return option(name, discordgo.ApplicationCommandOptionString, value)
+}
+
+// IntegerOption returns an integer option.
+func IntegerOption(name string, value int64) *discordgo.ApplicationCommandInteractionDataOption {
+	// numbers are decoded from JSON as float64
+	return option(name, discordgo.ApplicationCommandOptionInteger, float64(value))
+}
+
+// NumberOption returns a number option.
+func NumberOption(name string, value float64) *discordgo.ApplicationCommandInteractionDataOption {
+	return option(name, discordgo.ApplicationCommandOptionNumber, value)
+}
+
+// BooleanOption returns a boolean option.
+func BooleanOption(name string, value bool) *discordgo.ApplicationCommandInteractionDataOption {
+	return option(name, discordgo.ApplicationCommandOptionBoolean, value)
+}
+
+// UserOption returns a user option selecting the user with the ID. Use Resolved to include the user's details.
+func UserOption(name, userID string) *discordgo.ApplicationCommandInteractionDataOption {
+	return option(name, discordgo.ApplicationCommandOptionUser, userID)
+}
+
+// ChannelOption returns a channel option selecting the channel with the ID.
+func ChannelOption(name, channelID string) *discordgo.ApplicationCommandInteractionDataOption {
+	return option(name, discordgo.ApplicationCommandOptionChannel, channelID)
+}
+
+// RoleOption returns a role option selecting the role with the ID.
+func RoleOption(name, roleID string) *discordgo.ApplicationCommandInteractionDataOption {
+	return option(name, discordgo.ApplicationCommandOptionRole, roleID)
+}
+
+// AttachmentOption returns an attachment option selecting the attachment with the ID.
+func AttachmentOption(name, attachmentID string) *discordgo.ApplicationCommandInteractionDataOption {
+	return option(name, discordgo.ApplicationCommandOptionAttachment, attachmentID)
+}
+
+// Subcommand returns an option invoking the subcommand with the options.
+func Subcommand(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:    name,
+		Type:    discordgo.ApplicationCommandOptionSubCommand,
+		Options: options,
+	}
+}
+
+// SubcommandGroup returns an option invoking the subcommand of the group.
+func SubcommandGroup(name string, subcommand *discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:    name,
+		Type:    discordgo.ApplicationCommandOptionSubCommandGroup,
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{subcommand},
+	}
+}
+
+// Focused marks the option as the one being autocompleted.
+func Focused(o *discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	o.Focused = true
+
+	return o
+}
+
+func option(name string, t discordgo.ApplicationCommandOptionType, value any) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: t, Value: value}
+}
@@ -0,0 +1,68 @@
+package routertest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/interactions/router"
+	"github.com/elliotwms/bot/interactions/router/replay"
+	"github.com/stretchr/testify/require"
+)
+
+// Replay replays the fixtures recorded in the directory (see replay.Recorder) against the router, in a subtest per
+// fixture, requiring the requests made in reply to match those recorded. Request bodies are compared as JSON, so
+// handlers which generate values (e.g. the custom IDs of router.Confirm) can not be replayed.
+func Replay(t *testing.T, r *router.Router, dir string) {
+	t.Helper()
+
+	fixtures, err := replay.Load(dir)
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures, "no fixtures in %s", dir)
+
+	for _, f := range fixtures {
+		t.Run(f.Interaction.ID, func(t *testing.T) {
+			s, rec := NewSession()
+
+			r.HandleWithContext(t.Context(), s, &discordgo.InteractionCreate{Interaction: f.Interaction})
+
+			requireRequests(t, f.Requests, replayed(rec, f.Interaction.Token))
+		})
+	}
+}
+
+// replayed returns the requests recorded for the interaction with the token, as they are recorded by replay.Recorder
+func replayed(rec *Recorder, token string) []replay.Request {
+	var requests []replay.Request
+
+	for _, req := range rec.Requests() {
+		if !strings.Contains(req.Path, "/"+token) {
+			continue
+		}
+
+		requests = append(requests, replay.Request{
+			Method: req.Method,
+			Path:   replay.RedactPath(req.Path, token),
+			Body:   req.Body,
+		})
+	}
+
+	return requests
+}
+
+func requireRequests(t *testing.T, want, got []replay.Request) {
+	t.Helper()
+
+	require.Len(t, got, len(want), "unexpected number of requests")
+
+	for n := range want {
+		require.Equal(t, want[n].Method+" "+want[n].Path, got[n].Method+" "+got[n].Path, "request %d", n)
+
+		if len(want[n].Body) == 0 {
+			require.Empty(t, got[n].Body, "request %d", n)
+			continue
+		}
+
+		require.JSONEq(t, string(want[n].Body), string(got[n].Body), "request %d", n)
+	}
+}
@@ -0,0 +1,55 @@
+package routertest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/interactions/router"
+	"github.com/elliotwms/bot/interactions/router/replay"
+	"github.com/stretchr/testify/require"
+)
+
+func newEchoRouter() *router.Router {
+	r := router.New(router.WithDeferredResponse(true))
+	r.RegisterCommand("echo", discordgo.ChatApplicationCommand, func(ctx context.Context, _ *discordgo.Session, _ *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+		rs := router.ResponderFromContext(ctx)
+		text := router.OptionsOf(data)["text"].StringValue()
+
+		if err := rs.Respond(ctx, &discordgo.InteractionResponseData{Content: text}); err != nil {
+			return err
+		}
+
+		return rs.Respond(ctx, &discordgo.InteractionResponseData{Content: text + " again"})
+	})
+
+	return r
+}
+
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	// record an interaction handled by a router through a recording session
+	rec, err := replay.NewRecorder(dir)
+	require.NoError(t, err)
+
+	recorded := newEchoRouter()
+	recorded.Use(rec.Middleware())
+	t.Cleanup(recorded.Close)
+
+	s, sent := NewSession()
+	s.Client.Transport = rec.Transport(sent)
+
+	recorded.Handle(s, Command("echo", Options(StringOption("text", "hi"))))
+
+	fixtures, err := replay.Load(dir)
+	require.NoError(t, err)
+	require.Len(t, fixtures, 1)
+	require.Len(t, fixtures[0].Requests, 3)
+
+	// replay it against a new router
+	r := newEchoRouter()
+	t.Cleanup(r.Close)
+
+	Replay(t, r, dir)
+}
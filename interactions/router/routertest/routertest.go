@@ -0,0 +1,210 @@
+// Package routertest provides utilities for testing the handlers of a router.Router in-process, without Discord: a
+// recording session which serves Discord's interaction endpoints in memory, fixtures for the interactions a router
+// handles, and assertions on the responses, edits and follow-ups sent in reply.
+//
+//	h := routertest.New(t, r)
+//
+//	res := h.Dispatch(routertest.Command("echo", routertest.Options(routertest.StringOption("text", "hi"))))
+//
+//	res.RequireContent("hi")
+package routertest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/interactions/router"
+	"github.com/stretchr/testify/require"
+)
+
+// Harness dispatches interactions to a router through a recording session.
+type Harness struct {
+	t        testing.TB
+	router   *router.Router
+	session  *discordgo.Session
+	recorder *Recorder
+}
+
+// New returns a harness which dispatches interactions to the router. The router is closed when the test finishes.
+func New(t testing.TB, r *router.Router) *Harness {
+	s, rec := NewSession()
+
+	t.Cleanup(r.Close)
+
+	return &Harness{t: t, router: r, session: s, recorder: rec}
+}
+
+// Session returns the recording session interactions are dispatched with.
+func (h *Harness) Session() *discordgo.Session {
+	return h.session
+}
+
+// Recorder returns the recorder of the harness' session.
+func (h *Harness) Recorder() *Recorder {
+	return h.recorder
+}
+
+// Dispatch routes the interaction, returning once it has been handled. Handlers which reply asynchronously (e.g. after
+// a collector or prompt) can be awaited with Result.Eventually.
+func (h *Harness) Dispatch(i *discordgo.InteractionCreate) *Result {
+	h.router.HandleWithContext(h.t.Context(), h.session, i)
+
+	return &Result{t: h.t, Interaction: i, recorder: h.recorder}
+}
+
+// Go routes the interaction in a goroutine, for handlers which block until another interaction is dispatched (e.g.
+// router.Confirm).
+func (h *Harness) Go(i *discordgo.InteractionCreate) *Result {
+	go h.router.HandleWithContext(h.t.Context(), h.session, i)
+
+	return &Result{t: h.t, Interaction: i, recorder: h.recorder}
+}
+
+// Result is what was sent in reply to a dispatched interaction.
+type Result struct {
+	t           testing.TB
+	Interaction *discordgo.InteractionCreate
+	recorder    *Recorder
+}
+
+// Responses returns the initial responses sent to the interaction.
+func (r *Result) Responses() []Response {
+	return r.recorder.Responses(r.Interaction.Token)
+}
+
+// Original returns the original response, including any edits, or nil if it has not been sent or was deleted.
+func (r *Result) Original() *discordgo.Message {
+	return r.recorder.Original(r.Interaction.Token)
+}
+
+// Edits returns the edits of the original response and follow-up messages.
+func (r *Result) Edits() []Edit {
+	return r.recorder.Edits(r.Interaction.Token)
+}
+
+// Followups returns the follow-up messages which have not been deleted, including any edits.
+func (r *Result) Followups() []*discordgo.Message {
+	return r.recorder.Followups(r.Interaction.Token)
+}
+
+// RequireResponse requires the interaction to have been responded to exactly once, with the type, returning the
+// response.
+func (r *Result) RequireResponse(t discordgo.InteractionResponseType) Response {
+	r.t.Helper()
+
+	responses := r.Responses()
+	require.Len(r.t, responses, 1, "expected one response")
+	require.Equal(r.t, t, responses[0].Type, "unexpected response type")
+
+	return responses[0]
+}
+
+// RequireNoResponse requires the interaction not to have been responded to.
+func (r *Result) RequireNoResponse() {
+	r.t.Helper()
+
+	require.Empty(r.t, r.Responses(), "expected no response")
+}
+
+// RequireContent requires the content of the original response, including any edits, to be the content.
+func (r *Result) RequireContent(content string) {
+	r.t.Helper()
+
+	original := r.Original()
+	require.NotNil(r.t, original, "expected an original response")
+	require.Equal(r.t, content, original.Content)
+}
+
+// RequireEphemeral requires the original response to be ephemeral.
+func (r *Result) RequireEphemeral() {
+	r.t.Helper()
+
+	original := r.Original()
+	require.NotNil(r.t, original, "expected an original response")
+	require.NotZero(r.t, original.Flags&discordgo.MessageFlagsEphemeral, "expected an ephemeral response")
+}
+
+// RequireEdits requires n edits of the original response and follow-up messages, returning them.
+func (r *Result) RequireEdits(n int) []Edit {
+	r.t.Helper()
+
+	edits := r.Edits()
+	require.Len(r.t, edits, n, "unexpected number of edits")
+
+	return edits
+}
+
+// RequireFollowups requires n follow-up messages, returning them.
+func (r *Result) RequireFollowups(n int) []*discordgo.Message {
+	r.t.Helper()
+
+	followups := r.Followups()
+	require.Len(r.t, followups, n, "unexpected number of follow-ups")
+
+	return followups
+}
+
+// RequireChoices requires the interaction to have been answered with the autocomplete choices' names, in order.
+func (r *Result) RequireChoices(names ...string) {
+	r.t.Helper()
+
+	res := r.RequireResponse(discordgo.InteractionApplicationCommandAutocompleteResult)
+
+	var data struct {
+		Choices []*discordgo.ApplicationCommandOptionChoice `json:"choices"`
+	}
+	require.NoError(r.t, json.Unmarshal(res.Data, &data))
+
+	var got []string
+	for _, c := range data.Choices {
+		got = append(got, c.Name)
+	}
+
+	require.Equal(r.t, names, got)
+}
+
+// Eventually waits up to the timeout for the condition, e.g. for a handler which replies asynchronously to have
+// responded.
+func (r *Result) Eventually(condition func(r *Result) bool, timeout time.Duration) {
+	r.t.Helper()
+
+	require.Eventually(r.t, func() bool { return condition(r) }, timeout, time.Millisecond)
+}
+
+// Responded reports whether the interaction has been responded to, for use with Eventually.
+func Responded(r *Result) bool {
+	return len(r.Responses()) > 0
+}
+
+// HasFollowups returns a condition reporting whether the interaction has at least n follow-up messages, for use with
+// Eventually.
+func HasFollowups(n int) func(r *Result) bool {
+	return func(r *Result) bool {
+		return len(r.Followups()) >= n
+	}
+}
+
+// Components returns the custom IDs of the buttons and select menus in the message, in order.
+func Components(m *discordgo.Message) []string {
+	var ids []string
+
+	for _, c := range m.Components {
+		row, ok := c.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+
+		for _, c := range row.Components {
+			switch c := c.(type) {
+			case *discordgo.Button:
+				ids = append(ids, c.CustomID)
+			case *discordgo.SelectMenu:
+				ids = append(ids, c.CustomID)
+			}
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,159 @@
+package routertest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/interactions/router"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHarness_Command(t *testing.T) {
+	r := router.New()
+	r.RegisterCommand("echo", discordgo.ChatApplicationCommand, func(ctx context.Context, _ *discordgo.Session, _ *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+		return router.ResponderFromContext(ctx).Respond(ctx, &discordgo.InteractionResponseData{
+			Content: router.OptionsOf(data)["text"].StringValue(),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+	})
+
+	h := New(t, r)
+
+	res := h.Dispatch(Command("echo", Options(Subcommand("loud", StringOption("text", "hi")))))
+
+	res.RequireResponse(discordgo.InteractionResponseChannelMessageWithSource)
+	res.RequireContent("hi")
+	res.RequireEphemeral()
+}
+
+func TestHarness_DeferredEditsAndFollowups(t *testing.T) {
+	r := router.New(router.WithDeferredResponse(true))
+	r.RegisterCommand("slow", discordgo.ChatApplicationCommand, func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, _ discordgo.ApplicationCommandInteractionData) error {
+		rs := router.ResponderFromContext(ctx)
+
+		if err := rs.Respond(ctx, &discordgo.InteractionResponseData{Content: "working"}); err != nil {
+			return err
+		}
+
+		if err := rs.Respond(ctx, &discordgo.InteractionResponseData{Content: "step 1"}); err != nil {
+			return err
+		}
+
+		return rs.Edit(ctx, &discordgo.InteractionResponseData{Content: "step 2"})
+	})
+
+	h := New(t, r)
+
+	res := h.Dispatch(Command("slow"))
+
+	res.RequireResponse(discordgo.InteractionResponseDeferredChannelMessageWithSource)
+	res.RequireContent("working")
+	res.RequireEphemeral()
+
+	edits := res.RequireEdits(2)
+	require.Equal(t, "@original", edits[0].MessageID)
+	require.Equal(t, "step 2", edits[1].Message.Content)
+
+	followups := res.RequireFollowups(1)
+	require.Equal(t, "step 2", followups[0].Content)
+	require.Equal(t, followups[0].ID, edits[1].MessageID)
+}
+
+func TestHarness_Confirm(t *testing.T) {
+	confirmed := make(chan bool, 1)
+
+	r := router.New()
+	r.RegisterCommand("delete", discordgo.ChatApplicationCommand, func(ctx context.Context, _ *discordgo.Session, _ *discordgo.InteractionCreate, _ discordgo.ApplicationCommandInteractionData) error {
+		ok, err := router.Confirm(ctx, router.ResponderFromContext(ctx), "Are you sure?")
+		confirmed <- ok
+
+		return err
+	})
+
+	h := New(t, r)
+
+	res := h.Go(Command("delete"))
+	res.Eventually(Responded, time.Second)
+
+	buttons := Components(res.RequireResponse(discordgo.InteractionResponseChannelMessageWithSource).Message)
+	require.Len(t, buttons, 2)
+
+	click := h.Dispatch(Button(buttons[0]))
+	click.RequireResponse(discordgo.InteractionResponseUpdateMessage)
+
+	require.True(t, <-confirmed)
+}
+
+func TestHarness_Modal(t *testing.T) {
+	var values map[string]string
+
+	r := router.New()
+	r.RegisterModal("feedback", func(ctx context.Context, _ *discordgo.Session, _ *discordgo.InteractionCreate, data discordgo.ModalSubmitInteractionData) error {
+		values = make(map[string]string)
+		for _, row := range data.Components {
+			for _, c := range row.(*discordgo.ActionsRow).Components {
+				input := c.(*discordgo.TextInput)
+				values[input.CustomID] = input.Value
+			}
+		}
+
+		return nil
+	})
+
+	h := New(t, r)
+
+	res := h.Dispatch(Modal("feedback", map[string]string{"rating": "5", "comment": "great"}))
+
+	res.RequireNoResponse()
+	require.Equal(t, map[string]string{"rating": "5", "comment": "great"}, values)
+}
+
+func TestHarness_Autocomplete(t *testing.T) {
+	r := router.New()
+	r.RegisterAutocomplete("colour", func(_ context.Context, _ *discordgo.Session, _ *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+		require.True(t, data.Options[0].Focused)
+
+		return []*discordgo.ApplicationCommandOptionChoice{
+			{Name: data.Options[0].StringValue() + "een", Value: "green"},
+		}, nil
+	})
+
+	h := New(t, r)
+
+	h.Dispatch(Autocomplete("colour", Options(Focused(StringOption("name", "gr"))))).
+		RequireChoices("green")
+}
+
+func TestHarness_UserCommand(t *testing.T) {
+	var target *discordgo.User
+
+	r := router.New()
+	r.RegisterUserCommand("Profile", func(_ context.Context, _ *discordgo.Session, _ *discordgo.InteractionCreate, user *discordgo.User, _ *discordgo.Member) error {
+		target = user
+		return nil
+	})
+
+	h := New(t, r)
+
+	h.Dispatch(UserCommand("Profile", &discordgo.User{ID: "target", Username: "target"}, InDM()))
+
+	require.Equal(t, "target", target.ID)
+}
+
+func TestFixtures_Unique(t *testing.T) {
+	a, b := Command("foo"), Command("foo")
+
+	require.NotEqual(t, a.ID, b.ID)
+	require.NotEqual(t, a.Token, b.Token)
+}
+
+func TestFixtures_InDM(t *testing.T) {
+	i := Command("foo", InDM(), ByUser(&discordgo.User{ID: "other"}))
+
+	require.Nil(t, i.Member)
+	require.Equal(t, "other", i.User.ID)
+	require.Empty(t, i.GuildID)
+	require.True(t, router.InvocationOf(i.Interaction).InBotDM())
+}
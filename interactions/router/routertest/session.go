@@ -0,0 +1,303 @@
+package routertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/interactions/router/replay"
+)
+
+// Request is a request made by a recording session.
+type Request struct {
+	Method string
+	Path   string
+	// Body is the JSON body of the request, or the JSON payload of a multipart request
+	Body []byte
+}
+
+// Response is an initial response to an interaction.
+type Response struct {
+	Type discordgo.InteractionResponseType
+	// Data is the response's data as sent
+	Data json.RawMessage
+	// Message is the response's data read as a message, as InteractionResponseData can not be unmarshalled with
+	// components
+	Message *discordgo.Message
+	// CustomID and Title are set for modal responses
+	CustomID string
+	Title    string
+}
+
+// Edit is an edit of the original response or a follow-up message.
+type Edit struct {
+	// MessageID is the ID of the edited message, or "@original" for the original response
+	MessageID string
+	// Data is the edit as sent
+	Data json.RawMessage
+	// Message is the message after the edit was applied
+	Message *discordgo.Message
+}
+
+// Recorder records the REST calls made by a recording session, implementing the interaction endpoints in memory:
+// interaction callbacks, and getting, editing and deleting the original response and follow-up messages. Other
+// requests are recorded and answered with an empty object.
+type Recorder struct {
+	mu           sync.Mutex
+	requests     []Request
+	interactions map[string]*conversation
+	messageID    int
+}
+
+// conversation is what was sent in response to an interaction, keyed by its token
+type conversation struct {
+	responses []Response
+	original  *discordgo.Message
+	edits     []Edit
+	followups []*discordgo.Message
+}
+
+// NewSession returns a session whose REST calls are recorded and served in memory by the returned Recorder, instead
+// of being sent to Discord.
+func NewSession() (*discordgo.Session, *Recorder) {
+	rec := &Recorder{interactions: make(map[string]*conversation)}
+
+	s, _ := discordgo.New("Bot token")
+	s.Client = &http.Client{Transport: rec}
+	// fail fast instead of retrying, as every request is served in memory
+	s.MaxRestRetries = 0
+
+	return s, rec
+}
+
+// Requests returns every request made by the session, in order.
+func (r *Recorder) Requests() []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Request(nil), r.requests...)
+}
+
+// Responses returns the initial responses sent to the interaction with the token.
+func (r *Recorder) Responses(token string) []Response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Response(nil), r.conversation(token).responses...)
+}
+
+// Original returns the original response to the interaction with the token, including any edits, or nil if it has not
+// been sent or was deleted.
+func (r *Recorder) Original(token string) *discordgo.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.conversation(token).original
+}
+
+// Edits returns the edits of the original response and follow-up messages of the interaction with the token.
+func (r *Recorder) Edits(token string) []Edit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Edit(nil), r.conversation(token).edits...)
+}
+
+// Followups returns the follow-up messages of the interaction with the token which have not been deleted, including
+// any edits.
+func (r *Recorder) Followups(token string) []*discordgo.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*discordgo.Message(nil), r.conversation(token).followups...)
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := replay.RequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests = append(r.requests, Request{Method: req.Method, Path: req.URL.Path, Body: body})
+
+	status, res := r.serve(req.Method, strings.Split(strings.TrimPrefix(req.URL.Path, "/api/v"+discordgo.APIVersion+"/"), "/"), body)
+
+	return respond(req, status, res)
+}
+
+// serve handles the request to the path's segments, returning the status and the value of the response body
+func (r *Recorder) serve(method string, path []string, body []byte) (int, any) {
+	switch {
+	// interactions/{id}/{token}/callback
+	case method == http.MethodPost && len(path) == 4 && path[0] == "interactions" && path[3] == "callback":
+		r.callback(path[2], body)
+		return http.StatusNoContent, nil
+
+	// webhooks/{app}/{token}
+	case method == http.MethodPost && len(path) == 3 && path[0] == "webhooks":
+		return http.StatusOK, r.followup(path[2], body)
+
+	// webhooks/{app}/{token}/messages/{id}
+	case len(path) == 5 && path[0] == "webhooks" && path[3] == "messages":
+		return r.message(method, path[2], path[4], body)
+
+	// users/{id}
+	case method == http.MethodGet && len(path) == 2 && path[0] == "users":
+		return http.StatusOK, &discordgo.User{ID: path[1]}
+	}
+
+	return http.StatusOK, struct{}{}
+}
+
+func (r *Recorder) callback(token string, body []byte) {
+	var res struct {
+		Type discordgo.InteractionResponseType `json:"type"`
+		Data json.RawMessage                   `json:"data"`
+	}
+	_ = json.Unmarshal(body, &res)
+
+	response := Response{Type: res.Type, Data: res.Data, Message: &discordgo.Message{}}
+	if len(res.Data) > 0 {
+		_ = json.Unmarshal(res.Data, response.Message)
+
+		var modal struct {
+			CustomID string `json:"custom_id"`
+			Title    string `json:"title"`
+		}
+		_ = json.Unmarshal(res.Data, &modal)
+		response.CustomID, response.Title = modal.CustomID, modal.Title
+	}
+
+	c := r.conversation(token)
+	c.responses = append(c.responses, response)
+
+	switch res.Type {
+	case discordgo.InteractionResponseChannelMessageWithSource,
+		discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		discordgo.InteractionResponseUpdateMessage:
+		c.original = r.newMessage(response.Message)
+	}
+}
+
+func (r *Recorder) followup(token string, body []byte) *discordgo.Message {
+	m := &discordgo.Message{}
+	_ = json.Unmarshal(body, m)
+	m = r.newMessage(m)
+
+	c := r.conversation(token)
+	c.followups = append(c.followups, m)
+
+	return m
+}
+
+func (r *Recorder) message(method, token, id string, body []byte) (int, any) {
+	c := r.conversation(token)
+
+	m, i := c.original, -1
+	if id != "@original" {
+		m = nil
+
+		for n, f := range c.followups {
+			if f.ID == id {
+				m, i = f, n
+			}
+		}
+	}
+
+	if m == nil {
+		return http.StatusNotFound, &discordgo.APIErrorMessage{Code: discordgo.ErrCodeUnknownMessage, Message: "Unknown Message"}
+	}
+
+	switch method {
+	case http.MethodGet:
+		return http.StatusOK, m
+	case http.MethodPatch:
+		edited := edit(m, body)
+		c.edits = append(c.edits, Edit{MessageID: id, Data: body, Message: edited})
+
+		if i < 0 {
+			c.original = edited
+		} else {
+			c.followups[i] = edited
+		}
+
+		return http.StatusOK, edited
+	case http.MethodDelete:
+		if i < 0 {
+			c.original = nil
+		} else {
+			c.followups = append(c.followups[:i], c.followups[i+1:]...)
+		}
+
+		return http.StatusNoContent, nil
+	}
+
+	return http.StatusMethodNotAllowed, nil
+}
+
+// edit returns a copy of the message with the fields set by the edit replaced. Message's unmarshaller replaces every
+// field, so the edit is merged with the message as JSON.
+func edit(m *discordgo.Message, patch []byte) *discordgo.Message {
+	var fields map[string]json.RawMessage
+	b, _ := json.Marshal(m)
+	_ = json.Unmarshal(b, &fields)
+
+	var changes map[string]json.RawMessage
+	_ = json.Unmarshal(patch, &changes)
+	maps.Copy(fields, changes)
+
+	edited := &discordgo.Message{}
+	b, _ = json.Marshal(fields)
+	_ = json.Unmarshal(b, edited)
+
+	return edited
+}
+
+// newMessage assigns the message an ID
+func (r *Recorder) newMessage(m *discordgo.Message) *discordgo.Message {
+	r.messageID++
+
+	created := *m
+	created.ID = strconv.Itoa(r.messageID)
+
+	return &created
+}
+
+func (r *Recorder) conversation(token string) *conversation {
+	c, ok := r.interactions[token]
+	if !ok {
+		c = &conversation{}
+		r.interactions[token] = c
+	}
+
+	return c
+}
+
+func respond(req *http.Request, status int, v any) (*http.Response, error) {
+	res := &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       http.NoBody,
+		Request:    req,
+	}
+
+	if v != nil {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Body = io.NopCloser(bytes.NewReader(b))
+	}
+
+	return res, nil
+}
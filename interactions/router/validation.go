@@ -0,0 +1,163 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/i18n"
+)
+
+// Options are the options of a command invocation by name, including the options of the invoked subcommand.
+type Options map[string]*discordgo.ApplicationCommandInteractionDataOption
+
+// OptionsOf returns the options of the command invocation, including the options of the invoked subcommand.
+func OptionsOf(data discordgo.ApplicationCommandInteractionData) Options {
+	options := make(Options)
+	collectOptions(options, data.Options)
+
+	return options
+}
+
+func collectOptions(options Options, data []*discordgo.ApplicationCommandInteractionDataOption) {
+	for _, o := range data {
+		switch o.Type {
+		case discordgo.ApplicationCommandOptionSubCommand, discordgo.ApplicationCommandOptionSubCommandGroup:
+			collectOptions(options, o.Options)
+		default:
+			options[o.Name] = o
+		}
+	}
+}
+
+// Violation describes an invalid option.
+type Violation struct {
+	// Option is the name of the invalid option
+	Option string
+	// Key is the catalog key of the message describing the violation, which is translated if the router has a catalog
+	Key string
+	// Message is the message used when the catalog has no message for the key
+	Message string
+	// Args are the values of the message's placeholders, as key-value pairs
+	Args []any
+}
+
+// Rule checks the options of a command invocation, returning a violation for each invalid option. Rules for options
+// which were not provided should return no violations, leaving required options to Discord.
+type Rule func(options Options) []Violation
+
+// WithRules validates the command's options with the rules before the handler is called. If any option is invalid,
+// the handler is not called, and the invocation is answered with an ephemeral message listing each violation in the
+// user's locale.
+func WithRules(rules ...Rule) RouteOption {
+	return func(r *commandRoute) {
+		r.rules = append(r.rules, rules...)
+	}
+}
+
+// Match requires the string option to match the regular expression.
+func Match(option string, re *regexp.Regexp) Rule {
+	return func(options Options) []Violation {
+		o, ok := options[option]
+		if !ok || re.MatchString(o.StringValue()) {
+			return nil
+		}
+
+		return []Violation{{Option: option, Key: "validation.match", Message: "is not in the expected format"}}
+	}
+}
+
+// OneOf requires the string option to be one of the values, for when there are too many values for choices.
+func OneOf(option string, values ...string) Rule {
+	return func(options Options) []Violation {
+		o, ok := options[option]
+		if !ok || slices.Contains(values, o.StringValue()) {
+			return nil
+		}
+
+		return []Violation{{
+			Option:  option,
+			Key:     "validation.one_of",
+			Message: "must be one of {values}",
+			Args:    []any{"values", strings.Join(values, ", ")},
+		}}
+	}
+}
+
+// NotOneOf disallows the values for the string option.
+func NotOneOf(option string, values ...string) Rule {
+	return func(options Options) []Violation {
+		o, ok := options[option]
+		if !ok || !slices.Contains(values, o.StringValue()) {
+			return nil
+		}
+
+		return []Violation{{
+			Option:  option,
+			Key:     "validation.not_one_of",
+			Message: "can not be {value}",
+			Args:    []any{"value", o.StringValue()},
+		}}
+	}
+}
+
+// Greater requires the integer or number option to be greater than another, e.g. an end after a start.
+func Greater(option, other string) Rule {
+	return func(options Options) []Violation {
+		o, ok := options[option]
+		than, otherOK := options[other]
+		if !ok || !otherOK || numberValue(o) > numberValue(than) {
+			return nil
+		}
+
+		return []Violation{{
+			Option:  option,
+			Key:     "validation.greater",
+			Message: "must be greater than {other}",
+			Args:    []any{"other", other},
+		}}
+	}
+}
+
+// numberValue returns the value of an integer or number option
+func numberValue(o *discordgo.ApplicationCommandInteractionDataOption) float64 {
+	if o.Type == discordgo.ApplicationCommandOptionInteger {
+		return float64(o.IntValue())
+	}
+
+	return o.FloatValue()
+}
+
+// validate returns the violations of the route's rules
+func (r commandRoute) validate(data discordgo.ApplicationCommandInteractionData) []Violation {
+	if len(r.rules) == 0 {
+		return nil
+	}
+
+	options := OptionsOf(data)
+
+	var violations []Violation
+	for _, rule := range r.rules {
+		violations = append(violations, rule(options)...)
+	}
+
+	return violations
+}
+
+// invalidOptions lists the violations in the locale of the translator carried by ctx
+func invalidOptions(ctx context.Context, violations []Violation) *discordgo.InteractionResponseData {
+	t := i18n.FromContext(ctx)
+
+	lines := []string{t.TDefault("validation.invalid", "Please fix the following options:")}
+	for _, v := range violations {
+		lines = append(lines, fmt.Sprintf("- **%s**: %s", v.Option, t.TDefault(v.Key, v.Message, v.Args...)))
+	}
+
+	return &discordgo.InteractionResponseData{
+		Content: strings.Join(lines, "\n"),
+		Flags:   discordgo.MessageFlagsEphemeral,
+	}
+}
@@ -0,0 +1,86 @@
+package router
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+)
+
+func stringOption(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value}
+}
+
+func integerOption(name string, value int) *discordgo.ApplicationCommandInteractionDataOption {
+	// option values are unmarshalled from JSON as float64
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionInteger, Value: float64(value)}
+}
+
+func TestRules(t *testing.T) {
+	tests := map[string]struct {
+		rule    Rule
+		options []*discordgo.ApplicationCommandInteractionDataOption
+		valid   bool
+	}{
+		"match":              {Match("code", regexp.MustCompile(`^[A-Z]{3}$`)), []*discordgo.ApplicationCommandInteractionDataOption{stringOption("code", "ABC")}, true},
+		"match invalid":      {Match("code", regexp.MustCompile(`^[A-Z]{3}$`)), []*discordgo.ApplicationCommandInteractionDataOption{stringOption("code", "abc")}, false},
+		"match missing":      {Match("code", regexp.MustCompile(`^[A-Z]{3}$`)), nil, true},
+		"one of":             {OneOf("colour", "red", "blue"), []*discordgo.ApplicationCommandInteractionDataOption{stringOption("colour", "red")}, true},
+		"one of invalid":     {OneOf("colour", "red", "blue"), []*discordgo.ApplicationCommandInteractionDataOption{stringOption("colour", "green")}, false},
+		"not one of":         {NotOneOf("name", "admin"), []*discordgo.ApplicationCommandInteractionDataOption{stringOption("name", "bob")}, true},
+		"not one of invalid": {NotOneOf("name", "admin"), []*discordgo.ApplicationCommandInteractionDataOption{stringOption("name", "admin")}, false},
+		"greater":            {Greater("end", "start"), []*discordgo.ApplicationCommandInteractionDataOption{integerOption("start", 1), integerOption("end", 2)}, true},
+		"greater invalid":    {Greater("end", "start"), []*discordgo.ApplicationCommandInteractionDataOption{integerOption("start", 2), integerOption("end", 2)}, false},
+		"greater missing":    {Greater("end", "start"), []*discordgo.ApplicationCommandInteractionDataOption{integerOption("end", 2)}, true},
+		"subcommand options": {NotOneOf("name", "admin"), []*discordgo.ApplicationCommandInteractionDataOption{{
+			Name:    "sub",
+			Type:    discordgo.ApplicationCommandOptionSubCommand,
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{stringOption("name", "admin")},
+		}}, false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			violations := tt.rule(OptionsOf(discordgo.ApplicationCommandInteractionData{Options: tt.options}))
+
+			require.Equal(t, tt.valid, len(violations) == 0, "violations: %v", violations)
+		})
+	}
+}
+
+func TestRouter_ApplicationCommand_Rules(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_handler_is_registered_for_command_with_rules("foo", Greater("end", "start"), NotOneOf("name", "admin"))
+
+	when.
+		the_router_is_called_for_command_in_locale_with_options("foo", discordgo.EnglishUS, integerOption("start", 1), integerOption("end", 2), stringOption("name", "bob"))
+
+	then.
+		the_handler_should_have_been_called_n_times(1)
+
+	when.
+		the_router_is_called_for_command_in_locale_with_options("foo", discordgo.EnglishUS, integerOption("start", 2), integerOption("end", 1), stringOption("name", "admin"))
+
+	then.
+		the_last_response_should_be(discordgo.InteractionResponseChannelMessageWithSource, "Please fix the following options:\n- **end**: must be greater than start\n- **name**: can not be admin").and().
+		the_last_response_should_be_ephemeral().and().
+		the_handler_should_have_been_called_n_times(1)
+}
+
+func TestRouter_ApplicationCommand_Rules_Localized(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_catalog_with_validation_messages().and().
+		a_handler_is_registered_for_command_with_rules("foo", Greater("end", "start"))
+
+	when.
+		the_router_is_called_for_command_in_locale_with_options("foo", discordgo.French, integerOption("start", 2), integerOption("end", 1))
+
+	then.
+		the_last_response_should_be(discordgo.InteractionResponseChannelMessageWithSource, "Veuillez corriger les options suivantes :\n- **end**: doit être supérieur à start").and().
+		the_handler_should_have_been_called_n_times(0)
+}
@@ -0,0 +1,268 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/report"
+)
+
+// DefaultVariant is the name of the handler a command is registered with, when the command has variants.
+const DefaultVariant = "default"
+
+// ErrInvalidVariants is returned when a command's variants have negative weights, or the weights of the variants which
+// respond to invocations sum to more than 100.
+var ErrInvalidVariants = errors.New("invalid variants")
+
+// Bucketing is what invocations are bucketed by when choosing a command's variant.
+type Bucketing int
+
+const (
+	// BucketByGuild buckets invocations by guild, so that everyone in a guild gets the same variant. Invocations outside
+	// of guilds are bucketed by user.
+	BucketByGuild Bucketing = iota
+	// BucketByUser buckets invocations by user, so that each user gets the same variant in every guild.
+	BucketByUser
+)
+
+// Variant is an alternative version of a command's handler, e.g. a rewrite being rolled out.
+type Variant struct {
+	// Name labels the variant in logs, and is returned by VariantFromContext
+	Name    string
+	Handler ApplicationCommandHandler
+	// Weight is the percentage (0-100) of invocations routed to the variant. Buckets are assigned to variants in order,
+	// so the same users or guilds stay with a variant as its weight increases.
+	Weight int
+	// Guilds are the IDs of guilds whose invocations are always routed to the variant
+	Guilds []string
+	// Shadow runs the variant alongside the variant which responds, for comparing their outcomes, instead of routing
+	// invocations to it. Shadowed invocations are chosen by weight and guild independently of the other variants.
+	Shadow bool
+}
+
+// ShadowResult compares the outcome of a shadow variant with the outcome of the variant which responded to the same
+// invocation.
+type ShadowResult struct {
+	InteractionID string
+	// Route is the name of the command
+	Route string
+	// Variant is the name of the variant which responded, and Shadow the name of the shadow variant
+	Variant, Shadow string
+	// Err and ShadowErr are the errors returned by each variant, if any
+	Err, ShadowErr error
+	// Duration and ShadowDuration are how long each variant took to handle the invocation
+	Duration, ShadowDuration time.Duration
+}
+
+// WithVariants routes invocations of the command between the handler it is registered with (named DefaultVariant) and
+// the variants, bucketing invocations deterministically. The handler receives the invocations not routed to a variant.
+// Commands registered with invalid variants (see ValidateRouteOptions) ignore them, so the handler receives every
+// invocation.
+// The chosen variant is added to the router's logs and can be retrieved by middleware with VariantFromContext.
+//
+// Shadow variants are run after the responding variant returns, with a session and responder whose requests to
+// Discord are discarded, so they can not respond to the invocation. Shadow variants should not wait for other
+// interactions (e.g. with Confirm or Prompt), as they will never be routed to them. See WithShadowObserver to compare
+// their outcomes.
+func WithVariants(by Bucketing, variants ...Variant) RouteOption {
+	return func(r *commandRoute) {
+		r.bucketing = by
+		r.variants = variants
+	}
+}
+
+// WithShadowObserver sets a function called with the outcome of each shadow variant, e.g. to record metrics. Outcomes
+// are also logged.
+func WithShadowObserver(observe func(ctx context.Context, r ShadowResult)) Option {
+	return func(r *Router) {
+		r.shadowObserver = observe
+	}
+}
+
+// ValidateRouteOptions checks the route options would register a valid route, returning ErrInvalidVariants if the
+// variants' weights are invalid.
+func ValidateRouteOptions(opts ...RouteOption) error {
+	var route commandRoute
+	for _, o := range opts {
+		o(&route)
+	}
+
+	return route.validateVariants()
+}
+
+type variantKey struct{}
+
+// VariantFromContext returns the name of the variant handling the command invocation, or "" if the command has no
+// variants.
+func VariantFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(variantKey{}).(string)
+
+	return name
+}
+
+// choose returns the variant which responds to the invocation and the shadow variants run alongside it
+func (r commandRoute) choose(name string, i *discordgo.Interaction) (Variant, []Variant) {
+	chosen := Variant{Name: DefaultVariant, Handler: r.handler}
+	if len(r.variants) == 0 {
+		return chosen, nil
+	}
+
+	key := UserID(i)
+	if r.bucketing == BucketByGuild && i.GuildID != "" {
+		key = i.GuildID
+	}
+
+	var shadows []Variant
+	chose := false
+	b, offset := Bucket(name, key), 0
+
+	for _, v := range r.variants {
+		inGuild := i.GuildID != "" && slices.Contains(v.Guilds, i.GuildID)
+
+		if v.Shadow {
+			if inGuild || Bucket(name+":"+v.Name, key) < v.Weight {
+				shadows = append(shadows, v)
+			}
+
+			continue
+		}
+
+		if !chose && (inGuild || (b >= offset && b < offset+v.Weight)) {
+			chosen, chose = v, true
+		}
+
+		offset += v.Weight
+	}
+
+	return chosen, shadows
+}
+
+// validateVariants checks that the variants' weights are percentages, and that the weights of the variants which respond sum
+// to at most 100
+func (r commandRoute) validateVariants() error {
+	total := 0
+	for _, v := range r.variants {
+		if v.Weight < 0 || v.Weight > 100 {
+			return fmt.Errorf("%w: %q has weight %d, must be 0-100", ErrInvalidVariants, v.Name, v.Weight)
+		}
+
+		if !v.Shadow {
+			total += v.Weight
+		}
+	}
+
+	if total > 100 {
+		return fmt.Errorf("%w: weights sum to %d, must be at most 100", ErrInvalidVariants, total)
+	}
+
+	return nil
+}
+
+// variantNames returns the names of the route's variants, or nil if it has none
+func (r commandRoute) variantNames() []string {
+	if len(r.variants) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(r.variants))
+	for _, v := range r.variants {
+		names = append(names, v.Name)
+	}
+
+	return names
+}
+
+// Bucket deterministically assigns the key to one of 100 buckets for the name, e.g. of a route or a feature flag, so
+// that each name assigns a different set of keys to the same buckets.
+func Bucket(name, key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name + ":" + key))
+
+	return int(h.Sum32() % 100)
+}
+
+// shadow runs the shadow variants of the invocation in the background, comparing their outcome with the responding
+// variant's
+func (r *Router) shadow(ctx context.Context, s *discordgo.Session, e *discordgo.InteractionCreate, command discordgo.ApplicationCommandInteractionData, log *slog.Logger, shadows []Variant, result ShadowResult) {
+	if len(shadows) == 0 {
+		return
+	}
+
+	ss := shadowSession(s)
+	ctx = withResponder(context.WithoutCancel(ctx), r.Responder(ss, e))
+
+	for _, v := range shadows {
+		go func() {
+			res := result
+			res.Shadow = v.Name
+
+			start := time.Now()
+			res.ShadowErr = runShadow(context.WithValue(ctx, variantKey{}, v.Name), ss, e, command, v.Handler)
+			res.ShadowDuration = time.Since(start)
+
+			log.Info("Shadow variant handled command",
+				slog.String("shadow", v.Name),
+				slog.Bool("match", (res.Err == nil) == (res.ShadowErr == nil)),
+				slog.Any("error", res.Err),
+				slog.Any("shadow_error", res.ShadowErr),
+				slog.Duration("duration", res.Duration),
+				slog.Duration("shadow_duration", res.ShadowDuration),
+			)
+
+			if r.shadowObserver != nil {
+				r.shadowObserver(ctx, res)
+			}
+		}()
+	}
+}
+
+// runShadow calls the shadow variant's handler, returning any panic as a *report.PanicError
+func runShadow(ctx context.Context, s *discordgo.Session, e *discordgo.InteractionCreate, command discordgo.ApplicationCommandInteractionData, h ApplicationCommandHandler) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = report.Recovered(v)
+		}
+	}()
+
+	return h(ctx, s, e, command)
+}
+
+// shadowSession returns a session which shares the session's state, if any, but discards its requests to Discord
+func shadowSession(s *discordgo.Session) *discordgo.Session {
+	ss, _ := discordgo.New("")
+	if s != nil {
+		ss.Token = s.Token
+		ss.State = s.State
+		ss.StateEnabled = s.StateEnabled
+	}
+
+	ss.MaxRestRetries = 0
+	ss.Client = &http.Client{Transport: discardTransport{}}
+
+	return ss
+}
+
+// discardTransport answers every request with an empty object instead of sending it to Discord
+type discardTransport struct{}
+
+func (discardTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString("{}")),
+		Request:    req,
+	}, nil
+}
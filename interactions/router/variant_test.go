@@ -0,0 +1,172 @@
+package router
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_WithVariants(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_command_with_variants("foo", BucketByGuild,
+			Variant{Name: "v2", Weight: 100},
+		)
+
+	when.
+		the_router_is_called_for_command_in_guild("foo", "guild")
+
+	then.
+		the_variants_should_have_handled("v2").and().
+		the_response_content_should_be("v2")
+}
+
+func TestRouter_WithVariants_Default(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_command_with_variants("foo", BucketByGuild,
+			Variant{Name: "v2", Weight: 0},
+		)
+
+	when.
+		the_router_is_called_for_command_in_guild("foo", "guild")
+
+	then.
+		the_variants_should_have_handled(DefaultVariant)
+}
+
+func TestRouter_WithVariants_Guilds(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_command_with_variants("foo", BucketByGuild,
+			Variant{Name: "v2", Guilds: []string{"canary"}},
+		)
+
+	when.
+		the_router_is_called_for_command_in_guild("foo", "guild").and().
+		the_router_is_called_for_command_in_guild("foo", "canary")
+
+	then.
+		the_variants_should_have_handled(DefaultVariant, "v2")
+}
+
+func TestRouter_WithVariants_Shadow(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_shadow_observer().and().
+		a_command_with_variants("foo", BucketByGuild,
+			Variant{Name: "v2", Weight: 100, Shadow: true},
+		)
+
+	when.
+		the_router_is_called_for_command_in_guild("foo", "guild")
+
+	then.
+		the_shadow_result_should_be_observed(DefaultVariant, "v2").and().
+		the_variants_should_have_handled(DefaultVariant, "v2").and().
+		// the shadow variant's response is discarded
+		the_response_content_should_be(DefaultVariant)
+}
+
+func TestRouter_WithVariants_Routes(t *testing.T) {
+	r := New()
+	r.RegisterCommand("foo", discordgo.ChatApplicationCommand, nil, WithVariants(BucketByUser,
+		Variant{Name: "v2", Weight: 10},
+		Variant{Name: "v3", Shadow: true},
+	))
+
+	require.Equal(t, []string{"v2", "v3"}, r.Routes()[0].Variants)
+}
+
+func TestCommandRoute_Choose(t *testing.T) {
+	route := commandRoute{variants: []Variant{
+		{Name: "a", Weight: 25},
+		{Name: "b", Weight: 25},
+	}}
+
+	counts := make(map[string]int)
+	for n := range 1000 {
+		i := &discordgo.Interaction{GuildID: strconv.Itoa(n), Member: &discordgo.Member{User: &discordgo.User{ID: "user"}}}
+
+		v, shadows := route.choose("foo", i)
+		require.Empty(t, shadows)
+		counts[v.Name]++
+
+		// bucketing is deterministic
+		again, _ := route.choose("foo", i)
+		require.Equal(t, v.Name, again.Name)
+	}
+
+	require.InDelta(t, 250, counts["a"], 60)
+	require.InDelta(t, 250, counts["b"], 60)
+	require.InDelta(t, 500, counts[DefaultVariant], 60)
+}
+
+func TestCommandRoute_Choose_BucketByUser(t *testing.T) {
+	route := commandRoute{bucketing: BucketByUser, variants: []Variant{{Name: "a", Weight: 50}}}
+
+	user := &discordgo.Member{User: &discordgo.User{ID: "user"}}
+	first, _ := route.choose("foo", &discordgo.Interaction{GuildID: "1", Member: user})
+
+	// the user gets the same variant in every guild
+	for n := range 100 {
+		v, _ := route.choose("foo", &discordgo.Interaction{GuildID: strconv.Itoa(n), Member: user})
+		require.Equal(t, first.Name, v.Name)
+	}
+}
+
+func TestRouter_WithVariants_InvalidWeights(t *testing.T) {
+	given, when, then := NewRouterStage(t)
+
+	given.
+		a_command_with_variants("foo", BucketByGuild,
+			Variant{Name: "v2", Weight: 60},
+			Variant{Name: "v3", Weight: 60},
+		)
+
+	when.
+		the_router_is_called_for_command_in_guild("foo", "guild")
+
+	then.
+		// the invalid variants are ignored, so the handler receives the invocation without a variant
+		the_variants_should_have_handled("")
+}
+
+func TestValidateRouteOptions(t *testing.T) {
+	tests := map[string]struct {
+		variants []Variant
+		valid    bool
+	}{
+		"no variants":        {valid: true},
+		"weights up to 100":  {variants: []Variant{{Name: "a", Weight: 60}, {Name: "b", Weight: 40}}, valid: true},
+		"shadows not summed": {variants: []Variant{{Name: "a", Weight: 100}, {Name: "b", Weight: 100, Shadow: true}}, valid: true},
+		"weights over 100":   {variants: []Variant{{Name: "a", Weight: 60}, {Name: "b", Weight: 41}}},
+		"negative weight":    {variants: []Variant{{Name: "a", Weight: -1}}},
+		"shadow over 100":    {variants: []Variant{{Name: "a", Weight: 101, Shadow: true}}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateRouteOptions(WithVariants(BucketByGuild, tt.variants...))
+
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrInvalidVariants)
+			}
+		})
+	}
+}
+
+func TestShadowSession_NilSession(t *testing.T) {
+	ss := shadowSession(nil)
+
+	_, err := ss.InteractionResponse(&discordgo.Interaction{AppID: "app", Token: "token"})
+	require.NoError(t, err)
+}
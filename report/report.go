@@ -0,0 +1,72 @@
+// Package report reports errors and panics from interaction handlers and the bot, e.g. to an error tracker or a
+// Discord channel.
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// Report describes a failure.
+type Report struct {
+	// Err is the error which occurred. Panics are reported as a *PanicError.
+	Err error
+	// Source is what failed, e.g. "interaction", "entitlement", "migration" or "session"
+	Source string
+	// InteractionID is the ID of the interaction being handled, if any
+	InteractionID string
+	// Route is the name of the command or the custom ID being handled, if any
+	Route string
+	// UserID is the ID of the user who triggered the interaction, if any
+	UserID string
+	// GuildID is the ID of the guild the interaction was triggered in, if any
+	GuildID string
+	// Stack is the stack trace of a panic
+	Stack []byte
+	Time  time.Time
+}
+
+// Panicked reports whether the failure was a panic.
+func (r Report) Panicked() bool {
+	var p *PanicError
+
+	return errors.As(r.Err, &p)
+}
+
+// Reporter receives reports of failures. Implementations must be safe for concurrent use.
+type Reporter interface {
+	Report(ctx context.Context, r Report)
+}
+
+// ReporterFunc adapts a function to a Reporter.
+type ReporterFunc func(ctx context.Context, r Report)
+
+func (f ReporterFunc) Report(ctx context.Context, r Report) {
+	f(ctx, r)
+}
+
+// PanicError is a recovered panic.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+// Recovered returns a PanicError for the value returned by recover, capturing the stack. It must be called from the
+// deferred function which recovered.
+func Recovered(v any) *PanicError {
+	return &PanicError{Value: v, Stack: debug.Stack()}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+
+	return err
+}
@@ -0,0 +1,181 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotwms/bot/log"
+)
+
+const (
+	defaultRateLimit       = 5
+	defaultRateLimitPeriod = time.Minute
+
+	// embed limits, see https://discord.com/developers/docs/resources/message#embed-object-embed-limits
+	maxDescription = 4096
+	maxFieldValue  = 1024
+
+	colourError = 0xED4245
+)
+
+// Webhook is a Reporter which posts each report as an embed to a Discord channel through a webhook. Reports are rate
+// limited so that a storm of errors does not flood the channel, with the number of dropped reports noted on the next
+// report posted.
+type Webhook struct {
+	session   *discordgo.Session
+	id, token string
+	log       *slog.Logger
+
+	mu          sync.Mutex
+	limit       int
+	period      time.Duration
+	windowStart time.Time
+	sent        int
+	dropped     int
+	now         func() time.Time
+}
+
+type WebhookOption func(*Webhook)
+
+// WithRateLimit sets the maximum number of reports posted in each period.
+func WithRateLimit(limit int, period time.Duration) WebhookOption {
+	return func(w *Webhook) {
+		w.limit, w.period = limit, period
+	}
+}
+
+// WithLogger sets the logger used to log reports which could not be posted.
+func WithLogger(l *slog.Logger) WebhookOption {
+	return func(w *Webhook) {
+		w.log = l
+	}
+}
+
+// NewWebhook creates a Webhook which posts reports using the webhook's ID and token. By default, at most 5 reports are
+// posted each minute.
+func NewWebhook(s *discordgo.Session, webhookID, token string, opts ...WebhookOption) *Webhook {
+	w := &Webhook{
+		session: s,
+		id:      webhookID,
+		token:   token,
+		log:     slog.New(log.DiscardHandler),
+		limit:   defaultRateLimit,
+		period:  defaultRateLimitPeriod,
+		now:     time.Now,
+	}
+
+	for _, o := range opts {
+		o(w)
+	}
+
+	return w
+}
+
+func (w *Webhook) Report(ctx context.Context, r Report) {
+	dropped, ok := w.allow()
+	if !ok {
+		return
+	}
+
+	_, err := w.session.WebhookExecute(w.id, w.token, false, &discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed(r, dropped)},
+	}, discordgo.WithContext(ctx))
+	if err != nil {
+		w.log.Error("Failed to post report", log.WithErr(err), slog.String("report", message(r)))
+	}
+}
+
+// allow reports whether a report can be posted within the rate limit, returning the number of reports dropped since
+// the last report was posted
+func (w *Webhook) allow() (int, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+	if now.Sub(w.windowStart) >= w.period {
+		w.windowStart, w.sent = now, 0
+	}
+
+	if w.sent >= w.limit {
+		w.dropped++
+		return 0, false
+	}
+
+	w.sent++
+	dropped := w.dropped
+	w.dropped = 0
+
+	return dropped, true
+}
+
+// message describes the report's error, which may be missing
+func message(r Report) string {
+	if r.Err == nil {
+		return "unknown error"
+	}
+
+	return r.Err.Error()
+}
+
+func embed(r Report, dropped int) *discordgo.MessageEmbed {
+	title := "Error"
+	if r.Panicked() {
+		title = "Panic"
+	}
+
+	if r.Source != "" {
+		title += " in " + r.Source
+	}
+
+	e := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: truncate(message(r), maxDescription),
+		Color:       colourError,
+	}
+
+	if !r.Time.IsZero() {
+		e.Timestamp = r.Time.Format(time.RFC3339)
+	}
+
+	field := func(name, value string) {
+		if value != "" {
+			e.Fields = append(e.Fields, &discordgo.MessageEmbedField{Name: name, Value: value, Inline: true})
+		}
+	}
+
+	field("Route", r.Route)
+	field("Interaction", r.InteractionID)
+	if r.UserID != "" {
+		field("User", fmt.Sprintf("<@%s>", r.UserID))
+	}
+	field("Guild", r.GuildID)
+
+	if len(r.Stack) > 0 {
+		e.Fields = append(e.Fields, &discordgo.MessageEmbedField{
+			Name:  "Stack",
+			Value: "```\n" + truncate(string(r.Stack), maxFieldValue-8) + "\n```",
+		})
+	}
+
+	if dropped > 0 {
+		e.Footer = &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("%d earlier reports were dropped by the rate limit", dropped)}
+	}
+
+	return e
+}
+
+// truncate shortens s to at most n bytes, without splitting a character
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	s = strings.ToValidUTF8(s[:n-3], "")
+
+	return s + "..."
+}
@@ -0,0 +1,114 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookTransport records the messages posted to webhooks instead of sending them to Discord
+type webhookTransport struct {
+	mu       sync.Mutex
+	messages []discordgo.WebhookParams
+}
+
+func (t *webhookTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var params discordgo.WebhookParams
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		_ = json.Unmarshal(body, &params)
+	}
+
+	t.mu.Lock()
+	t.messages = append(t.messages, params)
+	t.mu.Unlock()
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString("{}")),
+		Request:    req,
+	}, nil
+}
+
+func newTestWebhook(opts ...WebhookOption) (*Webhook, *webhookTransport) {
+	transport := &webhookTransport{}
+
+	s, _ := discordgo.New("Bot token")
+	s.Client = &http.Client{Transport: transport}
+
+	return NewWebhook(s, "webhook", "token", opts...), transport
+}
+
+func TestWebhook_Report(t *testing.T) {
+	w, transport := newTestWebhook()
+
+	w.Report(context.Background(), Report{
+		Err:           Recovered("oops"),
+		Source:        "interaction",
+		InteractionID: "interaction",
+		Route:         "foo",
+		UserID:        "user",
+		GuildID:       "guild",
+		Stack:         []byte("goroutine 1 [running]:"),
+		Time:          time.Now(),
+	})
+
+	require.Len(t, transport.messages, 1)
+	require.Len(t, transport.messages[0].Embeds, 1)
+
+	e := transport.messages[0].Embeds[0]
+	require.Equal(t, "Panic in interaction", e.Title)
+	require.Equal(t, "panic: oops", e.Description)
+	require.Equal(t, []*discordgo.MessageEmbedField{
+		{Name: "Route", Value: "foo", Inline: true},
+		{Name: "Interaction", Value: "interaction", Inline: true},
+		{Name: "User", Value: "<@user>", Inline: true},
+		{Name: "Guild", Value: "guild", Inline: true},
+		{Name: "Stack", Value: "```\ngoroutine 1 [running]:\n```"},
+	}, e.Fields)
+}
+
+func TestWebhook_Report_NilError(t *testing.T) {
+	w, transport := newTestWebhook()
+
+	w.Report(context.Background(), Report{Source: "session"})
+
+	require.Len(t, transport.messages, 1)
+	require.Equal(t, "Error in session", transport.messages[0].Embeds[0].Title)
+	require.Equal(t, "unknown error", transport.messages[0].Embeds[0].Description)
+}
+
+func TestWebhook_Report_RateLimit(t *testing.T) {
+	w, transport := newTestWebhook(WithRateLimit(2, time.Minute))
+
+	now := time.Now()
+	w.now = func() time.Time { return now }
+
+	for range 5 {
+		w.Report(context.Background(), Report{Err: errors.New("failed")})
+	}
+
+	require.Len(t, transport.messages, 2)
+
+	now = now.Add(time.Minute)
+	w.Report(context.Background(), Report{Err: errors.New("failed")})
+
+	require.Len(t, transport.messages, 3)
+	require.Equal(t, "3 earlier reports were dropped by the rate limit", transport.messages[2].Embeds[0].Footer.Text)
+}
+
+func TestTruncate(t *testing.T) {
+	require.Equal(t, "abc", truncate("abc", 3))
+	require.Equal(t, "a...", truncate("abcde", 4))
+	require.Equal(t, "...", truncate("éééé", 4), "multi-byte characters should not be split")
+}